@@ -0,0 +1,80 @@
+package allocation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+const BlackLittermanAlgorithmName = "Black-Litterman"
+
+// BlackLitterman blends the returns implied by the policy weights (the market or benchmark weights)
+// and the look back covariance with investor views. See calculate.BlackLittermanReturns.
+// The policy weights are scaled to sum to one, so percent weights may be used.
+// The resulting weights maximize the mean-variance utility of the blended returns and satisfy Constraints;
+// the zero value Constraints only allows long positions.
+// Views returns are annualized. When RiskAversion or Tau are not set,
+// calculate.DefaultRiskAversion and calculate.DefaultBlackLittermanTau are used.
+type BlackLitterman struct {
+	RiskAversion float64
+	Tau          float64
+	Constraints  calculate.WeightConstraints
+
+	weights []float64
+	views   []calculate.View
+}
+
+func (*BlackLitterman) Name() string { return BlackLittermanAlgorithmName }
+
+func (alg *BlackLitterman) SetWeights(in []float64) {
+	alg.weights = in
+}
+
+func (alg *BlackLitterman) SetViews(views []calculate.View) {
+	alg.views = views
+}
+
+func (alg *BlackLitterman) SetConstraints(constraints calculate.WeightConstraints) {
+	alg.Constraints = constraints
+}
+
+func (alg *BlackLitterman) PolicyWeights(ctx context.Context, _ time.Time, assetReturns returns.Table, ws []float64) ([]float64, error) {
+	if len(alg.weights) != len(ws) {
+		return nil, errors.New("expected the number of policy weights to be the same as the number of assets")
+	}
+	marketWeights := slices.Clone(alg.weights)
+	scaleToUnitRange(marketWeights)
+	if isOnlyZeros(ws) {
+		copy(ws, marketWeights)
+	}
+
+	err := ensureEnoughLookBackReturns(assetReturns)
+	if err != nil {
+		return ws, err
+	}
+	if assetReturns.NumberOfRows() <= assetReturns.NumberOfColumns() {
+		// the covariance matrix can not be inverted
+		return ws, backtest.ErrorNotEnoughData{}
+	}
+
+	riskAversion, tau := alg.RiskAversion, alg.Tau
+	if riskAversion <= 0 {
+		riskAversion = calculate.DefaultRiskAversion
+	}
+	if tau <= 0 {
+		tau = calculate.DefaultBlackLittermanTau
+	}
+
+	vols, correlations := assetReturns.AnnualizedRisks(), assetReturns.CorrelationMatrix()
+	expectedReturns, err := calculate.BlackLittermanReturns(marketWeights, vols, correlations, riskAversion, tau, alg.views)
+	if err != nil {
+		return ws, err
+	}
+	err = calculate.MeanVarianceUtilityWeights(ctx, ws, expectedReturns, vols, correlations, riskAversion, alg.Constraints)
+	return ws, err
+}
@@ -4,6 +4,7 @@ import (
 	"slices"
 
 	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/calculate"
 )
 
 type Algorithm interface {
@@ -17,8 +18,13 @@ func NewDefaultAlgorithmsList() []Algorithm {
 		new(EqualWeights),
 		new(EqualInverseVariance),
 		new(EqualRiskContribution),
+		new(HierarchicalRiskParity),
 		new(EqualVolatility),
 		new(EqualInverseVolatility),
+		new(MinimumVariance),
+		new(MaximumSharpeRatio),
+		new(TargetVolatility),
+		new(BlackLitterman),
 	}
 }
 
@@ -40,3 +46,23 @@ func AlgorithmRequiresWeights(alg Algorithm) bool {
 	_, ok := alg.(WeightSetter)
 	return ok
 }
+
+// ConstraintSetter is implemented by optimizing algorithms that respect weight constraints.
+type ConstraintSetter interface {
+	SetConstraints(calculate.WeightConstraints)
+}
+
+// ViewSetter is implemented by algorithms that blend investor views into expected returns.
+type ViewSetter interface {
+	SetViews([]calculate.View)
+}
+
+// RiskFreeReturnSetter is implemented by algorithms that use an annualized risk-free return.
+type RiskFreeReturnSetter interface {
+	SetRiskFreeReturn(float64)
+}
+
+// TargetVolatilitySetter is implemented by algorithms that target an annualized volatility.
+type TargetVolatilitySetter interface {
+	SetTargetVolatility(float64)
+}
@@ -0,0 +1,34 @@
+package allocation
+
+import (
+	"context"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+const HierarchicalRiskParityAlgorithmName = "Hierarchical Risk Parity"
+
+// HierarchicalRiskParity allocates using calculate.HierarchicalRiskParityWeights.
+// To inspect the cluster tree, call calculate.HierarchicalClusters with the correlation matrix of the look back returns.
+type HierarchicalRiskParity struct{}
+
+func (*HierarchicalRiskParity) Name() string { return HierarchicalRiskParityAlgorithmName }
+
+func (*HierarchicalRiskParity) PolicyWeights(_ context.Context, _ time.Time, assetReturns returns.Table, ws []float64) ([]float64, error) {
+	if isOnlyZeros(ws) {
+		for i := range ws {
+			ws[i] = 1.0
+		}
+		scaleToUnitRange(ws)
+	}
+
+	err := ensureEnoughLookBackReturns(assetReturns)
+	if err != nil {
+		return ws, err
+	}
+
+	calculate.HierarchicalRiskParityWeights(ws, assetReturns.RisksFromStdDev(), assetReturns.CorrelationMatrix())
+	return ws, nil
+}
@@ -2,7 +2,9 @@ package allocation
 
 import (
 	"errors"
+	"math"
 
+	"github.com/portfoliotree/portfolio/backtest"
 	"github.com/portfoliotree/portfolio/returns"
 )
 
@@ -13,6 +15,15 @@ func ensureEnoughReturns(assetReturns returns.Table) error {
 	return nil
 }
 
+// ensureEnoughLookBackReturns is like ensureEnoughReturns but returns backtest.ErrorNotEnoughData,
+// so backtest.Run calculates the first policy on a later day when the look back window is too short.
+func ensureEnoughLookBackReturns(assetReturns returns.Table) error {
+	if ensureEnoughReturns(assetReturns) != nil {
+		return backtest.ErrorNotEnoughData{}
+	}
+	return nil
+}
+
 func isOnlyZeros(a []float64) bool {
 	for _, v := range a {
 		if v != 0 {
@@ -22,10 +33,12 @@ func isOnlyZeros(a []float64) bool {
 	return true
 }
 
+// scaleToUnitRange scales the weights so their gross exposure (the sum of the absolute weights) is one.
+// Long-only weights sum to one; long/short weights keep their signs even when the net sum is zero or negative.
 func scaleToUnitRange(list []float64) {
 	sum := 0.0
 	for _, v := range list {
-		sum += v
+		sum += math.Abs(v)
 	}
 	if sum == 0 {
 		return
@@ -0,0 +1,136 @@
+package allocation
+
+import (
+	"context"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+const (
+	MinimumVarianceAlgorithmName    = "Minimum Variance"
+	MaximumSharpeRatioAlgorithmName = "Maximum Sharpe Ratio"
+	TargetVolatilityAlgorithmName   = "Target Volatility"
+
+	// DefaultTargetVolatility is used by TargetVolatility when AnnualizedVolatility is not set.
+	DefaultTargetVolatility = 0.10
+)
+
+type MinimumVariance struct {
+	Constraints calculate.WeightConstraints
+}
+
+func (*MinimumVariance) Name() string { return MinimumVarianceAlgorithmName }
+
+func (alg *MinimumVariance) SetConstraints(constraints calculate.WeightConstraints) {
+	alg.Constraints = constraints
+}
+
+func (alg *MinimumVariance) PolicyWeights(ctx context.Context, _ time.Time, assetReturns returns.Table, ws []float64) ([]float64, error) {
+	if isOnlyZeros(ws) {
+		for i := range ws {
+			ws[i] = 1.0
+		}
+		scaleToUnitRange(ws)
+	}
+
+	err := ensureEnoughLookBackReturns(assetReturns)
+	if err != nil {
+		return ws, err
+	}
+
+	err = calculate.MinimumVarianceWeights(ctx, ws, assetReturns.RisksFromStdDev(), assetReturns.CorrelationMatrix(), alg.Constraints)
+	return ws, err
+}
+
+// MaximumSharpeRatio uses the arithmetic mean of the look back returns as expected returns.
+// The risk-free return is the arithmetic mean of RiskFreeReturns over the same look back.
+// When RiskFreeReturns is not set, the annualized RiskFreeReturn is used.
+type MaximumSharpeRatio struct {
+	RiskFreeReturns returns.List
+	RiskFreeReturn  float64
+	Constraints     calculate.WeightConstraints
+}
+
+func (*MaximumSharpeRatio) Name() string { return MaximumSharpeRatioAlgorithmName }
+
+func (alg *MaximumSharpeRatio) SetConstraints(constraints calculate.WeightConstraints) {
+	alg.Constraints = constraints
+}
+
+func (alg *MaximumSharpeRatio) SetRiskFreeReturn(annualized float64) {
+	alg.RiskFreeReturn = annualized
+}
+
+func (alg *MaximumSharpeRatio) PolicyWeights(ctx context.Context, _ time.Time, assetReturns returns.Table, ws []float64) ([]float64, error) {
+	if isOnlyZeros(ws) {
+		for i := range ws {
+			ws[i] = 1.0
+		}
+		scaleToUnitRange(ws)
+	}
+
+	err := ensureEnoughLookBackReturns(assetReturns)
+	if err != nil {
+		return ws, err
+	}
+
+	riskFree := alg.RiskFreeReturn
+	if len(alg.RiskFreeReturns) > 0 {
+		riskFree = alg.RiskFreeReturns.Between(assetReturns.LastTime(), assetReturns.FirstTime()).AnnualizedArithmeticReturn()
+	}
+	err = calculate.MaximumSharpeRatioWeights(ctx, ws,
+		assetReturns.AnnualizedArithmeticReturns(),
+		assetReturns.AnnualizedRisks(),
+		assetReturns.CorrelationMatrix(),
+		riskFree,
+		alg.Constraints,
+	)
+	return ws, err
+}
+
+// TargetVolatility maximizes the expected return (the arithmetic mean of the look back returns)
+// while keeping the expected annualized volatility at or below AnnualizedVolatility.
+type TargetVolatility struct {
+	AnnualizedVolatility float64
+	Constraints          calculate.WeightConstraints
+}
+
+func (*TargetVolatility) Name() string { return TargetVolatilityAlgorithmName }
+
+func (alg *TargetVolatility) SetConstraints(constraints calculate.WeightConstraints) {
+	alg.Constraints = constraints
+}
+
+func (alg *TargetVolatility) SetTargetVolatility(annualized float64) {
+	alg.AnnualizedVolatility = annualized
+}
+
+func (alg *TargetVolatility) PolicyWeights(ctx context.Context, _ time.Time, assetReturns returns.Table, ws []float64) ([]float64, error) {
+	if isOnlyZeros(ws) {
+		for i := range ws {
+			ws[i] = 1.0
+		}
+		scaleToUnitRange(ws)
+	}
+
+	err := ensureEnoughLookBackReturns(assetReturns)
+	if err != nil {
+		return ws, err
+	}
+
+	target := alg.AnnualizedVolatility
+	if target <= 0 {
+		target = DefaultTargetVolatility
+	}
+
+	err = calculate.TargetVolatilityWeights(ctx, ws,
+		assetReturns.AnnualizedArithmeticReturns(),
+		assetReturns.AnnualizedRisks(),
+		assetReturns.CorrelationMatrix(),
+		target,
+		alg.Constraints,
+	)
+	return ws, err
+}
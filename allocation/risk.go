@@ -8,11 +8,17 @@ import (
 	"github.com/portfoliotree/portfolio/returns"
 )
 
-type EqualRiskContribution struct{}
+type EqualRiskContribution struct {
+	Constraints calculate.WeightConstraints
+}
 
 func (*EqualRiskContribution) Name() string { return "Equal Risk Contribution" }
 
-func (*EqualRiskContribution) PolicyWeights(ctx context.Context, _ time.Time, assetReturns returns.Table, ws []float64) ([]float64, error) {
+func (alg *EqualRiskContribution) SetConstraints(constraints calculate.WeightConstraints) {
+	alg.Constraints = constraints
+}
+
+func (alg *EqualRiskContribution) PolicyWeights(ctx context.Context, _ time.Time, assetReturns returns.Table, ws []float64) ([]float64, error) {
 	if isOnlyZeros(ws) {
 		for i := range ws {
 			ws[i] = 1.0
@@ -25,6 +31,6 @@ func (*EqualRiskContribution) PolicyWeights(ctx context.Context, _ time.Time, as
 		return ws, err
 	}
 
-	err = calculate.EqualRiskContributionWeights(ctx, ws, assetReturns.RisksFromStdDev(), assetReturns.CorrelationMatrix())
+	err = calculate.EqualRiskContributionWeightsWithConstraints(ctx, ws, assetReturns.RisksFromStdDev(), assetReturns.CorrelationMatrix(), alg.Constraints)
 	return ws, err
 }
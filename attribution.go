@@ -0,0 +1,242 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// Segment groups assets by component ID for BrinsonAttribution.
+type Segment struct {
+	Name       string      `json:"name"       yaml:"name"       bson:"name"`
+	Components []Component `json:"components" yaml:"components" bson:"components"`
+}
+
+// AttributionPortfolio is a backtested portfolio.
+// Assets must have a column for each asset in Spec; it is usually the table passed to Specification.Backtest.
+type AttributionPortfolio struct {
+	Spec   *Specification
+	Result backtest.Result
+	Assets returns.Table
+}
+
+// BrinsonAttribution explains the active return of a portfolio over a benchmark portfolio by segment.
+type BrinsonAttribution struct {
+	Segments []string `json:"segments" bson:"segments"`
+	// Periods are sorted newest first.
+	Periods []AttributionPeriod `json:"periods" bson:"periods"`
+	// Cumulative links the effects of the periods with Carino coefficients so they sum to the compounded active return.
+	// Its time is the newest period, its segment weights are averages, and its segment returns are compounded.
+	Cumulative AttributionPeriod `json:"cumulative" bson:"cumulative"`
+}
+
+// AttributionPeriod has the Brinson-Fachler effects for one period. Segments are in the order of BrinsonAttribution.Segments.
+type AttributionPeriod struct {
+	Time            time.Time            `json:"time"            bson:"time"`
+	PortfolioReturn float64              `json:"portfolioReturn" bson:"portfolioReturn"`
+	BenchmarkReturn float64              `json:"benchmarkReturn" bson:"benchmarkReturn"`
+	ActiveReturn    float64              `json:"activeReturn"    bson:"activeReturn"`
+	Segments        []SegmentAttribution `json:"segments"        bson:"segments"`
+}
+
+// SegmentAttribution has the weights, returns, and effects of one segment.
+// When only one of the portfolios holds a segment, the return of the other is set to the return of the holder
+// so the active weight is credited to allocation.
+type SegmentAttribution struct {
+	Segment         string                   `json:"segment"         bson:"segment"`
+	PortfolioWeight float64                  `json:"portfolioWeight" bson:"portfolioWeight"`
+	BenchmarkWeight float64                  `json:"benchmarkWeight" bson:"benchmarkWeight"`
+	PortfolioReturn float64                  `json:"portfolioReturn" bson:"portfolioReturn"`
+	BenchmarkReturn float64                  `json:"benchmarkReturn" bson:"benchmarkReturn"`
+	Effects         calculate.BrinsonEffects `json:"effects"         bson:"effects"`
+}
+
+// NewBrinsonAttribution calculates Brinson-Fachler effects for each day both backtests have returns.
+// Each day uses the weights at the end of the previous day (scaled to sum to one) and the asset returns of the day,
+// so the first day of each backtest, transaction costs, and cash are not attributed.
+// When segments is empty, each asset is its own segment.
+// An error is returned when the portfolio or benchmark return of a period is -100% or less.
+func NewBrinsonAttribution(portfolio, benchmark AttributionPortfolio, segments []Segment) (BrinsonAttribution, error) {
+	names, portfolioSegments, benchmarkSegments, err := attributionSegments(portfolio.Spec, benchmark.Spec, segments)
+	if err != nil {
+		return BrinsonAttribution{}, err
+	}
+	result := BrinsonAttribution{Segments: names}
+	benchmarkTimes := benchmark.Result.ReturnsTable.Times()
+	for portfolioIndex, tm := range portfolio.Result.ReturnsTable.Times() {
+		pw, pr, ok, err := segmentWeightsAndReturns(portfolio, portfolioSegments, len(names), tm, portfolioIndex)
+		if err != nil {
+			return BrinsonAttribution{}, err
+		}
+		if !ok {
+			continue
+		}
+		// the returns table times are sorted newest first
+		benchmarkIndex, found := slices.BinarySearchFunc(benchmarkTimes, tm, func(et, t time.Time) int { return t.Compare(et) })
+		if !found {
+			continue
+		}
+		bw, br, ok, err := segmentWeightsAndReturns(benchmark, benchmarkSegments, len(names), tm, benchmarkIndex)
+		if err != nil {
+			return BrinsonAttribution{}, err
+		}
+		if !ok {
+			continue
+		}
+		result.Periods = append(result.Periods, newAttributionPeriod(tm, names, pw, bw, pr, br))
+	}
+	if len(result.Periods) == 0 {
+		return BrinsonAttribution{}, errors.New("portfolio and benchmark backtests do not have overlapping returns")
+	}
+	for _, period := range result.Periods {
+		if period.PortfolioReturn <= -1 || period.BenchmarkReturn <= -1 {
+			return BrinsonAttribution{}, fmt.Errorf("periods with a return of -100%% or less can not be linked: portfolio return %v and benchmark return %v on %s", period.PortfolioReturn, period.BenchmarkReturn, period.Time.Format(time.DateOnly))
+		}
+	}
+	result.Cumulative = linkAttributionPeriods(names, result.Periods)
+	return result, nil
+}
+
+func attributionSegments(portfolio, benchmark *Specification, segments []Segment) (names []string, portfolioSegments, benchmarkSegments []int, _ error) {
+	indexes := make(map[string]int)
+	for i, segment := range segments {
+		names = append(names, segment.Name)
+		for _, component := range segment.Components {
+			if _, found := indexes[component.ID]; found {
+				return nil, nil, nil, fmt.Errorf("asset %q is in more than one segment", component.ID)
+			}
+			indexes[component.ID] = i
+		}
+	}
+	assetSegments := func(spec *Specification) ([]int, error) {
+		result := make([]int, len(spec.Assets))
+		for i, asset := range spec.Assets {
+			index, found := indexes[asset.ID]
+			if !found {
+				if len(segments) > 0 {
+					return nil, fmt.Errorf("asset %q is not in a segment", asset.ID)
+				}
+				index = len(names)
+				indexes[asset.ID] = index
+				names = append(names, asset.ID)
+			}
+			result[i] = index
+		}
+		return result, nil
+	}
+	portfolioSegments, err := assetSegments(portfolio)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	benchmarkSegments, err = assetSegments(benchmark)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return names, portfolioSegments, benchmarkSegments, nil
+}
+
+// segmentWeightsAndReturns returns false when the backtest does not have weights on the day before tm.
+// The index is the row of tm in the backtest returns table.
+func segmentWeightsAndReturns(portfolio AttributionPortfolio, assetSegments []int, numberOfSegments int, tm time.Time, index int) (weights, segmentReturns []float64, _ bool, _ error) {
+	if index+1 >= len(portfolio.Result.Weights) {
+		return nil, nil, false, nil
+	}
+	assetWeights := portfolio.Result.Weights[index+1]
+	assetReturns, ok := portfolio.Assets.Row(tm)
+	if !ok {
+		return nil, nil, false, fmt.Errorf("asset returns are missing on %s", tm.Format(time.DateOnly))
+	}
+	if len(assetWeights) != len(assetSegments) || len(assetReturns) != len(assetSegments) {
+		return nil, nil, false, errAssetAndWeightsLenMismatch(portfolio.Spec)
+	}
+	var sum float64
+	for _, w := range assetWeights {
+		sum += w
+	}
+	if sum == 0 {
+		return nil, nil, false, nil
+	}
+	weights = make([]float64, numberOfSegments)
+	segmentReturns = make([]float64, numberOfSegments)
+	for i, segment := range assetSegments {
+		w := assetWeights[i] / sum
+		weights[segment] += w
+		segmentReturns[segment] += w * assetReturns[i]
+	}
+	for s := range segmentReturns {
+		if weights[s] != 0 {
+			segmentReturns[s] /= weights[s]
+		}
+	}
+	return weights, segmentReturns, true, nil
+}
+
+func newAttributionPeriod(tm time.Time, names []string, pw, bw, pr, br []float64) AttributionPeriod {
+	period := AttributionPeriod{
+		Time:     tm,
+		Segments: make([]SegmentAttribution, len(names)),
+	}
+	for s := range names {
+		switch {
+		case pw[s] == 0 && bw[s] != 0:
+			pr[s] = br[s]
+		case bw[s] == 0 && pw[s] != 0:
+			br[s] = pr[s]
+		}
+		period.PortfolioReturn += pw[s] * pr[s]
+		period.BenchmarkReturn += bw[s] * br[s]
+	}
+	period.ActiveReturn = period.PortfolioReturn - period.BenchmarkReturn
+	effects := calculate.BrinsonFachler(pw, bw, pr, br)
+	for s, name := range names {
+		period.Segments[s] = SegmentAttribution{
+			Segment:         name,
+			PortfolioWeight: pw[s],
+			BenchmarkWeight: bw[s],
+			PortfolioReturn: pr[s],
+			BenchmarkReturn: br[s],
+			Effects:         effects[s],
+		}
+	}
+	return period
+}
+
+func linkAttributionPeriods(names []string, periods []AttributionPeriod) AttributionPeriod {
+	portfolioReturns := make([]float64, len(periods))
+	benchmarkReturns := make([]float64, len(periods))
+	for i, period := range periods {
+		portfolioReturns[i] = period.PortfolioReturn
+		benchmarkReturns[i] = period.BenchmarkReturn
+	}
+	coefficients := calculate.CarinoLinkingCoefficients(portfolioReturns, benchmarkReturns)
+
+	cumulative := AttributionPeriod{
+		Time:            periods[0].Time,
+		PortfolioReturn: calculate.TimeWeightedReturn(portfolioReturns),
+		BenchmarkReturn: calculate.TimeWeightedReturn(benchmarkReturns),
+		Segments:        make([]SegmentAttribution, len(names)),
+	}
+	cumulative.ActiveReturn = cumulative.PortfolioReturn - cumulative.BenchmarkReturn
+	for s, name := range names {
+		segment := SegmentAttribution{Segment: name, PortfolioReturn: 1, BenchmarkReturn: 1}
+		for i, period := range periods {
+			ps, k := period.Segments[s], coefficients[i]
+			segment.PortfolioWeight += ps.PortfolioWeight / float64(len(periods))
+			segment.BenchmarkWeight += ps.BenchmarkWeight / float64(len(periods))
+			segment.PortfolioReturn *= 1 + ps.PortfolioReturn
+			segment.BenchmarkReturn *= 1 + ps.BenchmarkReturn
+			segment.Effects.Allocation += k * ps.Effects.Allocation
+			segment.Effects.Selection += k * ps.Effects.Selection
+			segment.Effects.Interaction += k * ps.Effects.Interaction
+		}
+		segment.PortfolioReturn--
+		segment.BenchmarkReturn--
+		cumulative.Segments[s] = segment
+	}
+	return cumulative
+}
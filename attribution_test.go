@@ -0,0 +1,130 @@
+package portfolio_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestNewBrinsonAttribution(t *testing.T) {
+	ctx := context.Background()
+	provider := portfoliotest.ComponentReturnsProvider()
+
+	backtest := func(t *testing.T, specYAML string) portfolio.AttributionPortfolio {
+		t.Helper()
+		pf, err := portfolio.ParseOneDocument(specYAML)
+		require.NoError(t, err)
+		assets, err := provider.ComponentReturnsTable(ctx, pf.Spec.Assets...)
+		require.NoError(t, err)
+		result, err := pf.Spec.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		return portfolio.AttributionPortfolio{Spec: &pf.Spec, Result: result, Assets: assets}
+	}
+
+	// language=yaml
+	pf := backtest(t, `---
+type: Portfolio
+spec:
+  assets: [AAPL, SPY, AGG]
+  policy:
+    weights: [20, 50, 30]
+    rebalancing_interval: Quarterly
+`)
+	// language=yaml
+	benchmark := backtest(t, `---
+type: Portfolio
+spec:
+  assets: [SPY, AGG]
+  policy:
+    weights: [60, 40]
+    rebalancing_interval: Monthly
+`)
+
+	checkAttribution := func(t *testing.T, attribution portfolio.BrinsonAttribution) {
+		t.Helper()
+		require.NotEmpty(t, attribution.Periods)
+		for _, period := range attribution.Periods {
+			var total float64
+			for _, segment := range period.Segments {
+				total += segment.Effects.Total()
+			}
+			require.InDelta(t, period.ActiveReturn, total, 1e-12)
+		}
+		var total float64
+		for _, segment := range attribution.Cumulative.Segments {
+			total += segment.Effects.Total()
+		}
+		assert.InDelta(t, attribution.Cumulative.ActiveReturn, total, 1e-9)
+		assert.Equal(t, attribution.Periods[0].Time, attribution.Cumulative.Time)
+	}
+
+	t.Run("by asset", func(t *testing.T) {
+		attribution, err := portfolio.NewBrinsonAttribution(pf, benchmark, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"AAPL", "SPY", "AGG"}, attribution.Segments)
+		checkAttribution(t, attribution)
+
+		period := attribution.Periods[0]
+		portfolioReturn, ok := pf.Result.Returns().Value(period.Time)
+		require.True(t, ok)
+		assert.InDelta(t, portfolioReturn, period.PortfolioReturn, 1e-12)
+		benchmarkReturn, ok := benchmark.Result.Returns().Value(period.Time)
+		require.True(t, ok)
+		assert.InDelta(t, benchmarkReturn, period.BenchmarkReturn, 1e-12)
+
+		aapl := period.Segments[0]
+		assert.Zero(t, aapl.BenchmarkWeight)
+		assert.Equal(t, aapl.PortfolioReturn, aapl.BenchmarkReturn, "the benchmark does not hold AAPL")
+		assert.Zero(t, aapl.Effects.Selection)
+		assert.Zero(t, aapl.Effects.Interaction)
+
+		oldest := attribution.Periods[len(attribution.Periods)-1].Time
+		index := slices.IndexFunc(pf.Result.ReturnsTable.Times(), oldest.Equal)
+		assert.Equal(t, pf.Result.ReturnsTable.NumberOfRows()-2, index, "the first backtest day does not have prior weights")
+	})
+
+	t.Run("by segment", func(t *testing.T) {
+		segments := []portfolio.Segment{
+			{Name: "Equities", Components: []portfolio.Component{{ID: "AAPL"}, {ID: "SPY"}}},
+			{Name: "Bonds", Components: []portfolio.Component{{ID: "AGG"}}},
+		}
+		attribution, err := portfolio.NewBrinsonAttribution(pf, benchmark, segments)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Equities", "Bonds"}, attribution.Segments)
+		checkAttribution(t, attribution)
+
+		equities := attribution.Cumulative.Segments[0]
+		assert.InDelta(t, 0.7, equities.PortfolioWeight, 0.05)
+		assert.InDelta(t, 0.6, equities.BenchmarkWeight, 0.05)
+		assert.NotZero(t, equities.Effects.Selection, "AAPL and SPY have different returns")
+	})
+
+	t.Run("missing segment", func(t *testing.T) {
+		_, err := portfolio.NewBrinsonAttribution(pf, benchmark, []portfolio.Segment{
+			{Name: "Equities", Components: []portfolio.Component{{ID: "AAPL"}, {ID: "SPY"}}},
+		})
+		assert.ErrorContains(t, err, `asset "AGG" is not in a segment`)
+	})
+
+	t.Run("total loss", func(t *testing.T) {
+		values := pf.Assets.ColumnValues()
+		day := slices.IndexFunc(pf.Assets.Times(), pf.Result.ReturnsTable.Times()[1].Equal)
+		require.GreaterOrEqual(t, day, 0)
+		columns := make([][]float64, len(values))
+		for c := range values {
+			columns[c] = slices.Clone(values[c])
+			columns[c][day] = -1.5
+		}
+		lost := pf
+		lost.Assets = returns.NewTableFromValues(pf.Assets.Times(), columns)
+		_, err := portfolio.NewBrinsonAttribution(lost, benchmark, nil)
+		assert.ErrorContains(t, err, "-100%")
+	})
+}
@@ -0,0 +1,47 @@
+package portfolio
+
+import (
+	"context"
+
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// BackfillComponentReturns fetches the returns for component from provider.
+// When the component has a Proxy, returns before the first return are backfilled from the proxy components
+// and the returned Splice records where the histories were joined. Otherwise, the Splice is the zero value.
+func BackfillComponentReturns(ctx context.Context, provider ComponentReturnsProvider, component Component) (returns.List, returns.Splice, error) {
+	list, err := provider.ComponentReturnsList(ctx, component)
+	if err != nil || component.Proxy == nil {
+		return list, returns.Splice{}, err
+	}
+	if len(component.Proxy.Components) == 1 {
+		proxy, err := provider.ComponentReturnsList(ctx, component.Proxy.Components[0])
+		if err != nil {
+			return nil, returns.Splice{}, err
+		}
+		return list.Backfill(proxy, component.Proxy.ScaleVolatility)
+	}
+	factors, err := provider.ComponentReturnsTable(ctx, component.Proxy.Components...)
+	if err != nil {
+		return nil, returns.Splice{}, err
+	}
+	return list.BackfillWithRegression(factors, component.Proxy.ScaleVolatility)
+}
+
+// BackfilledAssetReturns returns a table of the asset returns where assets with a Proxy are backfilled.
+// The splices are in the same order as the assets.
+func (pf *Specification) BackfilledAssetReturns(ctx context.Context, provider ComponentReturnsProvider) (returns.Table, []returns.Splice, error) {
+	var (
+		table   returns.Table
+		splices = make([]returns.Splice, len(pf.Assets))
+	)
+	for i, asset := range pf.Assets {
+		list, splice, err := BackfillComponentReturns(ctx, provider, asset)
+		if err != nil {
+			return returns.Table{}, nil, err
+		}
+		splices[i] = splice
+		table = table.AddColumn(list)
+	}
+	return table, splices, nil
+}
@@ -0,0 +1,59 @@
+package portfolio_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+)
+
+func TestSpecification_BackfilledAssetReturns(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets:
+    - id: GOOG
+      proxy:
+        components: [SPY]
+        scale_volatility: true
+    - id: META
+      proxy:
+        components: [SPY, AGG]
+    - AGG
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+	require.NoError(t, pf.Spec.Validate())
+
+	ctx := context.Background()
+	provider := portfoliotest.ComponentReturnsProvider()
+	table, splices, err := pf.Spec.BackfilledAssetReturns(ctx, provider)
+	require.NoError(t, err)
+
+	agg, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "AGG"})
+	require.NoError(t, err)
+	assert.Equal(t, agg.FirstTime(), table.FirstTime(), "the table is no longer truncated to the GOOG and META inception")
+
+	require.Len(t, splices, 3)
+	assert.Equal(t, date("2014-03-28"), splices[0].Time)
+	assert.Greater(t, splices[0].Backfilled, 0)
+	assert.NotEqual(t, 1.0, splices[0].Scale)
+	assert.Nil(t, splices[0].Regression)
+
+	assert.Equal(t, date("2012-05-21"), splices[1].Time)
+	require.NotNil(t, splices[1].Regression)
+	assert.Len(t, splices[1].Regression.Coefficients, 2)
+
+	assert.Zero(t, splices[2])
+
+	t.Run("invalid proxy", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Assets = []portfolio.Component{{ID: "GOOG", Proxy: &portfolio.Proxy{}}}
+		assert.ErrorContains(t, spec.Validate(), "proxy must list at least one component")
+	})
+}
@@ -0,0 +1,44 @@
+package backtestconfig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Threshold configures tolerance band rebalancing. Weights are expressed as fractions so
+// an Absolute value of 0.05 allows an asset to drift five percentage points from its policy weight.
+// A Relative value of 0.25 allows an asset with a policy weight of 0.4 to drift to 0.3 or 0.5.
+// When both are set, exceeding either band triggers a rebalance.
+type Threshold struct {
+	Absolute float64 `yaml:"absolute,omitempty" json:"absolute,omitempty" bson:"absolute,omitempty"`
+	Relative float64 `yaml:"relative,omitempty" json:"relative,omitempty" bson:"relative,omitempty"`
+}
+
+func (th Threshold) IsSet() bool { return th.Absolute != 0 || th.Relative != 0 }
+
+func (th Threshold) Validate() error {
+	var list []error
+	if th.Absolute < 0 || th.Absolute > 1 || math.IsNaN(th.Absolute) {
+		list = append(list, fmt.Errorf("absolute threshold %v must be between 0 and 1", th.Absolute))
+	}
+	if th.Relative < 0 || math.IsInf(th.Relative, 0) || math.IsNaN(th.Relative) {
+		list = append(list, fmt.Errorf("relative threshold %v must not be negative", th.Relative))
+	}
+	return errors.Join(list...)
+}
+
+// Exceeded returns true when any current weight has drifted outside the tolerance band
+// around the corresponding policy weight.
+func (th Threshold) Exceeded(currentWeights, policyWeights []float64) bool {
+	for i := range policyWeights {
+		drift := math.Abs(currentWeights[i] - policyWeights[i])
+		if th.Absolute > 0 && drift > th.Absolute {
+			return true
+		}
+		if th.Relative > 0 && drift > th.Relative*math.Abs(policyWeights[i]) {
+			return true
+		}
+	}
+	return false
+}
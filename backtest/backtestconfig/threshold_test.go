@@ -0,0 +1,41 @@
+package backtestconfig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+)
+
+var _ backtest.DriftFunc = backtestconfig.Threshold{}.Exceeded
+
+func TestThreshold_Exceeded(t *testing.T) {
+	policy := []float64{0.6, 0.4}
+	for _, tt := range []struct {
+		Name      string
+		Threshold backtestconfig.Threshold
+		Current   []float64
+		Expected  bool
+	}{
+		{Name: "within absolute band", Threshold: backtestconfig.Threshold{Absolute: 0.05}, Current: []float64{0.64, 0.36}, Expected: false},
+		{Name: "outside absolute band", Threshold: backtestconfig.Threshold{Absolute: 0.05}, Current: []float64{0.66, 0.34}, Expected: true},
+		{Name: "within relative band", Threshold: backtestconfig.Threshold{Relative: 0.25}, Current: []float64{0.51, 0.49}, Expected: false},
+		{Name: "outside relative band", Threshold: backtestconfig.Threshold{Relative: 0.2}, Current: []float64{0.51, 0.49}, Expected: true},
+		{Name: "either band", Threshold: backtestconfig.Threshold{Absolute: 0.5, Relative: 0.2}, Current: []float64{0.51, 0.49}, Expected: true},
+		{Name: "not set", Threshold: backtestconfig.Threshold{}, Current: []float64{1, 0}, Expected: false},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tt.Threshold.Exceeded(tt.Current, policy))
+		})
+	}
+}
+
+func TestThreshold_Validate(t *testing.T) {
+	assert.NoError(t, backtestconfig.Threshold{}.Validate())
+	assert.NoError(t, backtestconfig.Threshold{Absolute: 0.05, Relative: 0.2}.Validate())
+	assert.Error(t, backtestconfig.Threshold{Absolute: -0.05}.Validate())
+	assert.Error(t, backtestconfig.Threshold{Absolute: 5}.Validate())
+	assert.Error(t, backtestconfig.Threshold{Relative: -1}.Validate())
+}
@@ -0,0 +1,134 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// ContributionAnalysis splits the portfolio returns of a Result into asset contributions.
+type ContributionAnalysis struct {
+	// Daily has a column for each asset and a last column for the residual.
+	// An asset contribution is its weight at the end of the previous day (as a fraction of portfolio value) times its return.
+	// The residual is the rest of the portfolio return: transaction costs, the cash or financing leg, and the
+	// whole return of the first day (it does not have previous weights).
+	Daily returns.Table `json:"daily" bson:"daily"`
+	// Years are sorted newest first.
+	Years []ContributionSummary `json:"years" bson:"years"`
+	Total ContributionSummary   `json:"total" bson:"total"`
+}
+
+// ContributionSummary links daily contributions geometrically (with Carino coefficients) so the asset
+// contributions and the residual sum to the time weighted return.
+type ContributionSummary struct {
+	Label    string    `json:"label"    bson:"label"`
+	Start    time.Time `json:"start"    bson:"start"`
+	End      time.Time `json:"end"      bson:"end"`
+	Return   float64   `json:"return"   bson:"return"`
+	Assets   []float64 `json:"assets"   bson:"assets"`
+	Residual float64   `json:"residual" bson:"residual"`
+}
+
+// Contributions calculates the contributions of each asset to the portfolio returns.
+// The assets table must have a column for each asset and a row for each day in the result.
+// An error is returned when a portfolio return is -100% or less because Carino linking is not defined for those returns.
+func (result Result) Contributions(assets returns.Table) (ContributionAnalysis, error) {
+	times := result.ReturnsTable.Times()
+	if len(times) == 0 {
+		return ContributionAnalysis{}, errors.New("result does not have returns")
+	}
+	if len(result.Weights) != len(times) || len(result.NetExposures) != len(times) {
+		return ContributionAnalysis{}, errors.New("result does not have weights for each day")
+	}
+	portfolioReturns := result.Returns().Values()
+	for i, value := range portfolioReturns {
+		if value <= -1 {
+			return ContributionAnalysis{}, fmt.Errorf("contributions can not be linked over a return of -100%% or less: portfolio return %v on %s", value, times[i].Format(time.DateOnly))
+		}
+	}
+	numberOfAssets := assets.NumberOfColumns()
+
+	daily := make([][]float64, numberOfAssets+1)
+	for c := range daily {
+		daily[c] = make([]float64, len(times))
+	}
+	residual := daily[numberOfAssets]
+	for i, tm := range times {
+		assetReturns, ok := assets.Row(tm)
+		if !ok {
+			return ContributionAnalysis{}, fmt.Errorf("asset returns are missing on %s", tm.Format(time.DateOnly))
+		}
+		residual[i] = portfolioReturns[i]
+		if i+1 == len(times) {
+			continue
+		}
+		weights := result.ValueWeights(i + 1)
+		if len(weights) != numberOfAssets {
+			return ContributionAnalysis{}, fmt.Errorf("expected %d asset weights but got %d", numberOfAssets, len(weights))
+		}
+		for c := range weights {
+			daily[c][i] = weights[c] * assetReturns[c]
+			residual[i] -= daily[c][i]
+		}
+	}
+
+	analysis := ContributionAnalysis{
+		Daily: returns.NewTableFromValues(times, daily),
+		Total: newContributionSummary("Total", times, portfolioReturns, daily),
+	}
+	for end := 0; end < len(times); {
+		year := times[end].Year()
+		start := end
+		for start+1 < len(times) && times[start+1].Year() == year {
+			start++
+		}
+		columns := make([][]float64, len(daily))
+		for c := range daily {
+			columns[c] = daily[c][end : start+1]
+		}
+		analysis.Years = append(analysis.Years, newContributionSummary(strconv.Itoa(year), times[end:start+1], portfolioReturns[end:start+1], columns))
+		end = start + 1
+	}
+	return analysis, nil
+}
+
+// valueFractionScale returns the factor to convert end of day weights into fractions of portfolio value.
+// The weights of portfolios without leverage are stored drifted so they sum to one plus the return;
+// the net exposure of those portfolios is one.
+func valueFractionScale(weights []float64, netExposure float64) float64 {
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return 0
+	}
+	return netExposure / sum
+}
+
+func newContributionSummary(label string, times []time.Time, portfolioReturns []float64, daily [][]float64) ContributionSummary {
+	coefficients := calculate.CarinoLinkingCoefficients(portfolioReturns, make([]float64, len(portfolioReturns)))
+	summary := ContributionSummary{
+		Label:  label,
+		Start:  times[len(times)-1],
+		End:    times[0],
+		Return: calculate.TimeWeightedReturn(portfolioReturns),
+		Assets: make([]float64, len(daily)-1),
+	}
+	for c := range daily {
+		var linked float64
+		for i, k := range coefficients {
+			linked += k * daily[c][i]
+		}
+		if c < len(summary.Assets) {
+			summary.Assets[c] = linked
+		} else {
+			summary.Residual = linked
+		}
+	}
+	return summary
+}
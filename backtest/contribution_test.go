@@ -0,0 +1,118 @@
+package backtest_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestResult_Contributions(t *testing.T) {
+	assets := returns.NewTable([]returns.List{
+		{
+			{Time: date("2021-01-05"), Value: -0.02},
+			{Time: date("2021-01-04"), Value: 0.03},
+			{Time: date("2020-12-31"), Value: 0.01},
+			{Time: date("2020-12-30"), Value: 0.02},
+		},
+		{
+			{Time: date("2021-01-05"), Value: 0.01},
+			{Time: date("2021-01-04"), Value: -0.01},
+			{Time: date("2020-12-31"), Value: 0.02},
+			{Time: date("2020-12-30"), Value: -0.01},
+		},
+	})
+	end, start, _ := assets.EndAndStartDates()
+	halfAndHalf := allocationFunction(func(_ context.Context, _ time.Time, _ returns.Table, ws []float64) ([]float64, error) {
+		copy(ws, []float64{0.5, 0.5})
+		return ws, nil
+	})
+	result, err := backtest.Run(context.Background(), end, start, assets, halfAndHalf,
+		backtestconfig.WindowNotSet,
+		backtestconfig.Never(),
+		backtestconfig.Never(),
+	)
+	require.NoError(t, err)
+
+	analysis, err := result.Contributions(assets)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, analysis.Daily.NumberOfColumns())
+	assert.Equal(t, assets.Times(), analysis.Daily.Times())
+	daily := analysis.Daily.ColumnValues()
+	portfolioReturns := result.Returns().Values()
+
+	// the first day does not have previous weights
+	assert.Equal(t, []float64{0, 0, portfolioReturns[3]}, []float64{daily[0][3], daily[1][3], daily[2][3]})
+
+	// the weights drifted on the first day
+	growth := 1 + portfolioReturns[3]
+	assert.InDelta(t, 0.5*1.02/growth*0.01, daily[0][2], 1e-12)
+	assert.InDelta(t, 0.5*0.99/growth*0.02, daily[1][2], 1e-12)
+	for i := range portfolioReturns {
+		assert.InDelta(t, portfolioReturns[i], daily[0][i]+daily[1][i]+daily[2][i], 1e-12)
+	}
+	assert.InDelta(t, 0, daily[2][0], 1e-12, "there are no costs or cash")
+
+	total := analysis.Total
+	assert.Equal(t, "Total", total.Label)
+	assert.Equal(t, date("2020-12-30"), total.Start)
+	assert.Equal(t, date("2021-01-05"), total.End)
+	assert.InDelta(t, result.Returns().TimeWeightedReturn(), total.Return, 1e-12)
+	assert.InDelta(t, total.Return, floats.Sum(total.Assets)+total.Residual, 1e-12)
+
+	require.Len(t, analysis.Years, 2)
+	assert.Equal(t, "2021", analysis.Years[0].Label)
+	assert.Equal(t, date("2021-01-04"), analysis.Years[0].Start)
+	assert.Equal(t, "2020", analysis.Years[1].Label)
+	for _, year := range analysis.Years {
+		assert.InDelta(t, year.Return, floats.Sum(year.Assets)+year.Residual, 1e-12)
+	}
+
+	t.Run("leverage", func(t *testing.T) {
+		financing := returns.List{{Time: date("2021-01-04"), Value: 0.01}}
+		longShort := allocationFunction(func(_ context.Context, _ time.Time, _ returns.Table, ws []float64) ([]float64, error) {
+			copy(ws, []float64{1.5, -0.3})
+			return ws, nil
+		})
+		result, err := backtest.Run(context.Background(), end, start, assets, longShort,
+			backtestconfig.WindowNotSet,
+			backtestconfig.Never(),
+			backtestconfig.Never(),
+			backtest.WithLeverage(financing),
+		)
+		require.NoError(t, err)
+		analysis, err := result.Contributions(assets)
+		require.NoError(t, err)
+
+		row, ok := analysis.Daily.Row(date("2021-01-04"))
+		require.True(t, ok)
+		weights := result.Weights[2]
+		assert.InDelta(t, weights[0]*0.03, row[0], 1e-12)
+		assert.InDelta(t, weights[1]*-0.01, row[1], 1e-12)
+		assert.InDelta(t, (1-weights[0]-weights[1])*0.01, row[2], 1e-12, "the financing leg is in the residual")
+	})
+
+	t.Run("missing asset returns", func(t *testing.T) {
+		_, err := result.Contributions(assets.Between(date("2021-01-05"), date("2021-01-04")))
+		assert.ErrorContains(t, err, "asset returns are missing on 2020-12-31")
+	})
+
+	t.Run("total loss", func(t *testing.T) {
+		columns := result.ReturnsTable.ColumnValues()
+		portfolioReturns := slices.Clone(columns[backtest.PortfolioReturnsColumn])
+		portfolioReturns[1] = -1
+		lost := result
+		lost.ReturnsTable = returns.NewTableFromValues(result.ReturnsTable.Times(), [][]float64{portfolioReturns, columns[backtest.DailyRebalancedReturnsColumn]})
+		_, err := lost.Contributions(assets)
+		assert.ErrorContains(t, err, "-100%")
+	})
+}
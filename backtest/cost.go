@@ -0,0 +1,87 @@
+package backtest
+
+import (
+	"math"
+	"time"
+)
+
+// CostModel calculates the cost of trading from currentWeights to targetWeights.
+// The cost is a fraction of portfolio value so 0.001 is 10 basis points.
+type CostModel interface {
+	TransactionCost(today time.Time, currentWeights, targetWeights []float64) float64
+}
+
+type CostFunc func(today time.Time, currentWeights, targetWeights []float64) float64
+
+func (fn CostFunc) TransactionCost(today time.Time, currentWeights, targetWeights []float64) float64 {
+	return fn(today, currentWeights, targetWeights)
+}
+
+// FixedCost charges BasisPoints on every unit of weight traded (both buys and sells).
+type FixedCost struct {
+	BasisPoints float64 `json:"basisPoints" yaml:"basis_points" bson:"basisPoints"`
+}
+
+func (cost FixedCost) TransactionCost(_ time.Time, currentWeights, targetWeights []float64) float64 {
+	return cost.BasisPoints / basisPointsPerUnit * tradedWeight(currentWeights, targetWeights)
+}
+
+// SpreadCost charges half of each asset's bid-ask spread on the weight traded in that asset.
+// BasisPoints has the spread of each asset in column order; assets after the end of BasisPoints are traded without cost.
+type SpreadCost struct {
+	BasisPoints []float64 `json:"basisPoints" yaml:"basis_points" bson:"basisPoints"`
+}
+
+func (cost SpreadCost) TransactionCost(_ time.Time, currentWeights, targetWeights []float64) float64 {
+	sum := 0.0
+	for i := range targetWeights {
+		if i >= len(cost.BasisPoints) {
+			break
+		}
+		sum += math.Abs(targetWeights[i]-currentWeights[i]) * cost.BasisPoints[i] / basisPointsPerUnit / 2
+	}
+	return sum
+}
+
+// MarketImpactCost charges Coefficient * |trade|^Exponent on each unit of weight traded.
+// An Exponent of 0.5 gives the commonly used square-root impact curve.
+type MarketImpactCost struct {
+	Coefficient float64 `json:"coefficient" yaml:"coefficient" bson:"coefficient"`
+	Exponent    float64 `json:"exponent"    yaml:"exponent"    bson:"exponent"`
+}
+
+func (cost MarketImpactCost) TransactionCost(_ time.Time, currentWeights, targetWeights []float64) float64 {
+	sum := 0.0
+	for i := range targetWeights {
+		trade := math.Abs(targetWeights[i] - currentWeights[i])
+		sum += cost.Coefficient * math.Pow(trade, cost.Exponent) * trade
+	}
+	return sum
+}
+
+// CostModels sums the transaction costs of each model.
+type CostModels []CostModel
+
+func (models CostModels) TransactionCost(today time.Time, currentWeights, targetWeights []float64) float64 {
+	sum := 0.0
+	for _, model := range models {
+		sum += model.TransactionCost(today, currentWeights, targetWeights)
+	}
+	return sum
+}
+
+const basisPointsPerUnit = 10_000
+
+// Turnover returns the one-way turnover (half the sum of absolute weight changes) required
+// to trade from currentWeights to targetWeights.
+func Turnover(currentWeights, targetWeights []float64) float64 {
+	return tradedWeight(currentWeights, targetWeights) / 2
+}
+
+func tradedWeight(currentWeights, targetWeights []float64) float64 {
+	sum := 0.0
+	for i := range targetWeights {
+		sum += math.Abs(targetWeights[i] - currentWeights[i])
+	}
+	return sum
+}
@@ -0,0 +1,88 @@
+package backtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestTurnover(t *testing.T) {
+	assert.InDelta(t, 0.1, backtest.Turnover([]float64{0.6, 0.4}, []float64{0.5, 0.5}), 1e-9)
+	assert.Zero(t, backtest.Turnover([]float64{0.5, 0.5}, []float64{0.5, 0.5}))
+}
+
+func TestCostModels(t *testing.T) {
+	current, target := []float64{0.6, 0.4}, []float64{0.5, 0.5}
+	var today time.Time
+
+	t.Run("fixed", func(t *testing.T) {
+		cost := backtest.FixedCost{BasisPoints: 10}.TransactionCost(today, current, target)
+		assert.InDelta(t, 0.0002, cost, 1e-12)
+	})
+	t.Run("spread", func(t *testing.T) {
+		cost := backtest.SpreadCost{BasisPoints: []float64{10, 30}}.TransactionCost(today, current, target)
+		assert.InDelta(t, 0.1*0.0010/2+0.1*0.0030/2, cost, 1e-12)
+	})
+	t.Run("spread without a value for each asset", func(t *testing.T) {
+		cost := backtest.SpreadCost{BasisPoints: []float64{10}}.TransactionCost(today, current, target)
+		assert.InDelta(t, 0.1*0.0010/2, cost, 1e-12)
+	})
+	t.Run("market impact", func(t *testing.T) {
+		cost := backtest.MarketImpactCost{Coefficient: 0.1, Exponent: 0.5}.TransactionCost(today, current, target)
+		assert.InDelta(t, 2*0.1*0.316227766*0.1, cost, 1e-9)
+	})
+	t.Run("combined", func(t *testing.T) {
+		cost := backtest.CostModels{
+			backtest.FixedCost{BasisPoints: 10},
+			backtest.FixedCost{BasisPoints: 5},
+		}.TransactionCost(today, current, target)
+		assert.InDelta(t, 0.0003, cost, 1e-12)
+	})
+}
+
+func TestRun_WithCostModel(t *testing.T) {
+	assets := returns.NewTable([]returns.List{
+		{
+			{Time: date("2021-01-03"), Value: 0},
+			{Time: date("2021-01-02"), Value: 0.1},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+		{
+			{Time: date("2021-01-03"), Value: 0},
+			{Time: date("2021-01-02"), Value: -0.1},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+	})
+	end, start, _ := assets.EndAndStartDates()
+
+	result, err := backtest.Run(context.Background(), end, start, assets, testAlgorithm(),
+		backtestconfig.WindowNotSet,
+		backtestconfig.Never(),
+		backtestconfig.Daily(),
+		backtest.WithCostModel(backtest.FixedCost{BasisPoints: 100}),
+	)
+	require.NoError(t, err)
+
+	assert.InDeltaSlice(t, []float64{0, -0.001, 0}, result.Returns().Values(), 1e-9)
+	assert.InDeltaSlice(t, []float64{0, 0.001, 0}, result.TransactionCosts().Values(), 1e-9)
+	assert.InDeltaSlice(t, []float64{0, 0, 0}, result.DailyRebalancedReturns().Values(), 1e-9, "daily rebalanced returns are not charged")
+	assert.InDelta(t, 0.05, result.TotalTurnover, 1e-9)
+	assert.InDelta(t, 0.001, result.TotalCost, 1e-9)
+	assert.Equal(t, 3, result.ReturnsTable.NumberOfColumns())
+
+	result, err = backtest.Run(context.Background(), end, start, assets, testAlgorithm(),
+		backtestconfig.WindowNotSet,
+		backtestconfig.Never(),
+		backtestconfig.Daily(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ReturnsTable.NumberOfColumns(), "the transaction costs column is only added with a cost model")
+	assert.Empty(t, result.TransactionCosts())
+}
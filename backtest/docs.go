@@ -1,5 +1,8 @@
 // Package backtest calculates portfolio returns and asset weights from historic asset returns.
 // It re-balances asset weights and updates policies based on provided functions. See Run.
+// Transaction costs may be charged on rebalance days by passing WithCostModel to Run.
+// Levered and long/short portfolios with a cash (financing) leg are supported by passing WithLeverage to Run.
+// NewReport summarizes the performance of a Result and Result.Contributions splits its returns by asset.
 //
 // DailyRebalancedWithStaticWeights is a simplified "back-tester" for calculating daily rebalanced returns of a portfolio
 // given static policy asset weights.
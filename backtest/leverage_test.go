@@ -0,0 +1,88 @@
+package backtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestRun_WithLeverage(t *testing.T) {
+	assets := returns.NewTable([]returns.List{
+		{
+			{Time: date("2021-01-03"), Value: 0},
+			{Time: date("2021-01-02"), Value: 0.1},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+		{
+			{Time: date("2021-01-03"), Value: 0},
+			{Time: date("2021-01-02"), Value: -0.1},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+	})
+	financing := returns.List{
+		{Time: date("2021-01-02"), Value: 0.01},
+	}
+	end, start, _ := assets.EndAndStartDates()
+
+	longShort := allocationFunction(func(_ context.Context, _ time.Time, _ returns.Table, ws []float64) ([]float64, error) {
+		copy(ws, []float64{1.5, -0.3})
+		return ws, nil
+	})
+
+	t.Run("levered", func(t *testing.T) {
+		result, err := backtest.Run(context.Background(), end, start, assets, longShort,
+			backtestconfig.WindowNotSet,
+			backtestconfig.Never(),
+			backtestconfig.Never(),
+			backtest.WithLeverage(financing),
+		)
+		require.NoError(t, err)
+
+		// the cash weight is 1 - (1.5 - 0.3) = -0.2 so 20% is borrowed at the financing return
+		assert.InDeltaSlice(t, []float64{0, 1.5*0.1 + -0.3*-0.1 + -0.2*0.01, 0}, result.Returns().Values(), 1e-9)
+
+		require.Len(t, result.GrossExposures, 3)
+		assert.InDelta(t, 1.8, result.GrossExposures[2], 1e-9)
+		assert.InDelta(t, 1.2, result.NetExposures[2], 1e-9)
+
+		growth := 1 + result.Returns()[1].Value
+		long, short := 1.5*1.1/growth, -0.3*0.9/growth
+		assert.InDeltaSlice(t, []float64{long, short}, result.Weights[1], 1e-9)
+		assert.InDelta(t, long-short, result.GrossExposures[0], 1e-9)
+		assert.InDelta(t, long+short, result.NetExposures[0], 1e-9)
+	})
+
+	t.Run("not levered", func(t *testing.T) {
+		result, err := backtest.Run(context.Background(), end, start, assets, longShort,
+			backtestconfig.WindowNotSet,
+			backtestconfig.Never(),
+			backtestconfig.Never(),
+		)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 1.25*0.1+-0.25*-0.1, result.Returns()[1].Value, 1e-9)
+		assert.InDelta(t, 1.5, result.GrossExposures[2], 1e-9)
+		assert.InDelta(t, 1, result.NetExposures[2], 1e-9)
+	})
+
+	t.Run("percent weights", func(t *testing.T) {
+		percent := allocationFunction(func(_ context.Context, _ time.Time, _ returns.Table, ws []float64) ([]float64, error) {
+			copy(ws, []float64{60, 40})
+			return ws, nil
+		})
+		_, err := backtest.Run(context.Background(), end, start, assets, percent,
+			backtestconfig.WindowNotSet,
+			backtestconfig.Never(),
+			backtestconfig.Never(),
+			backtest.WithLeverage(financing),
+		)
+		assert.ErrorContains(t, err, "policy weights have a gross exposure of 100 which is more than the maximum leverage 10")
+	})
+}
@@ -0,0 +1,82 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// Option configures optional Run behavior.
+type Option func(*options)
+
+type options struct {
+	costModel     CostModel
+	driftExceeded DriftFunc
+
+	leverage         bool
+	financingReturns returns.List
+}
+
+func newOptions(list []Option) options {
+	var opts options
+	for _, opt := range list {
+		opt(&opts)
+	}
+	return opts
+}
+
+// WithCostModel charges the transaction cost calculated by model on each rebalance day.
+// The cost reduces the portfolio return on the day the rebalance happens.
+// Daily rebalanced returns are not charged transaction costs.
+func WithCostModel(model CostModel) Option {
+	return func(opts *options) {
+		opts.costModel = model
+	}
+}
+
+// WithDriftTrigger only allows a rebalance when the rebalancing TriggerFunc passed to Run returns true
+// and exceeded returns true for the drifted portfolio weights. Pass a daily TriggerFunc to check for
+// drift every day.
+func WithDriftTrigger(exceeded DriftFunc) Option {
+	return func(opts *options) {
+		opts.driftExceeded = exceeded
+	}
+}
+
+// MaximumLeverage is the largest gross exposure (the sum of the absolute policy weights) allowed by WithLeverage.
+// Larger weights are most likely percentages rather than fractions of portfolio value.
+const MaximumLeverage = 10.0
+
+// WithLeverage uses policy weights as fractions of portfolio value instead of scaling them to sum to one.
+// This allows gross exposure above one and negative (short) weights.
+// Run returns an error when the gross exposure of the policy weights is more than MaximumLeverage.
+// The remainder, one minus the sum of the weights, is held in cash and earns the financing return for the day.
+// When the remainder is negative it is borrowed and the financing return is paid.
+// On days missing from financingReturns (or when it is nil) cash earns nothing.
+func WithLeverage(financingReturns returns.List) Option {
+	return func(opts *options) {
+		opts.leverage = true
+		opts.financingReturns = financingReturns
+	}
+}
+
+func (opts options) financingReturn(today time.Time) float64 {
+	value, _ := opts.financingReturns.Value(today)
+	return value
+}
+
+func (opts options) checkPolicyWeights(weights []float64) error {
+	if !opts.leverage {
+		return nil
+	}
+	gross := 0.0
+	for _, w := range weights {
+		gross += math.Abs(w)
+	}
+	if gross > MaximumLeverage {
+		return fmt.Errorf("policy weights have a gross exposure of %v which is more than the maximum leverage %v: weights must be fractions of portfolio value", gross, MaximumLeverage)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package backtest
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// ValueAtRiskConfidenceLevel is the confidence level of Metrics.ValueAtRisk.
+const ValueAtRiskConfidenceLevel = 0.95
+
+// TrailingYears are the trailing periods in a ReportColumn.
+var TrailingYears = []int{1, 3, 5, 10}
+
+// Report has performance metrics for the portfolio and daily rebalanced returns of a Result.
+type Report struct {
+	Portfolio       ReportColumn `json:"portfolio"       bson:"portfolio"`
+	DailyRebalanced ReportColumn `json:"dailyRebalanced" bson:"dailyRebalanced"`
+}
+
+// ReportColumn has metrics for the whole backtest, each calendar year, and the trailing periods.
+// Years are sorted newest first. Trailing periods longer than the backtest are left out.
+type ReportColumn struct {
+	Overall  Metrics   `json:"overall"  bson:"overall"`
+	Years    []Metrics `json:"years"    bson:"years"`
+	Trailing []Metrics `json:"trailing" bson:"trailing"`
+}
+
+// Metrics are annualized using the frequency of the returns (see returns.DetectFrequency).
+// Metrics that can not be calculated (for example a ratio with a zero denominator) are zero.
+// The benchmark-relative metrics are zero when no benchmark is provided.
+// MaxDrawdown is a positive fraction of the peak value and ValueAtRisk is a negative annual return.
+type Metrics struct {
+	Label string    `json:"label" bson:"label"`
+	Start time.Time `json:"start" bson:"start"`
+	End   time.Time `json:"end"   bson:"end"`
+
+	TotalReturn                float64 `json:"totalReturn"                bson:"totalReturn"`
+	AnnualizedReturn           float64 `json:"annualizedReturn"           bson:"annualizedReturn"`
+	AnnualizedArithmeticReturn float64 `json:"annualizedArithmeticReturn" bson:"annualizedArithmeticReturn"`
+	AnnualizedRisk             float64 `json:"annualizedRisk"             bson:"annualizedRisk"`
+	DownsideRisk               float64 `json:"downsideRisk"               bson:"downsideRisk"`
+	SharpeRatio                float64 `json:"sharpeRatio"                bson:"sharpeRatio"`
+	SortinoRatio               float64 `json:"sortinoRatio"               bson:"sortinoRatio"`
+	MaxDrawdown                float64 `json:"maxDrawdown"                bson:"maxDrawdown"`
+	CalmarRatio                float64 `json:"calmarRatio"                bson:"calmarRatio"`
+	UlcerIndex                 float64 `json:"ulcerIndex"                 bson:"ulcerIndex"`
+	ValueAtRisk                float64 `json:"valueAtRisk"                bson:"valueAtRisk"`
+
+	BenchmarkMetrics `bson:",inline"`
+}
+
+// BenchmarkMetrics compare portfolio returns to benchmark returns.
+// Metrics that can not be calculated (for example when the benchmark does not vary) are zero.
+type BenchmarkMetrics struct {
+	// ExcessReturn is the annualized time weighted return of the portfolio minus that of the benchmark.
+	ExcessReturn     float64 `json:"excessReturn"     bson:"excessReturn"`
+	TrackingError    float64 `json:"trackingError"    bson:"trackingError"`
+	InformationRatio float64 `json:"informationRatio" bson:"informationRatio"`
+	Beta             float64 `json:"beta"             bson:"beta"`
+	UpCaptureRatio   float64 `json:"upCaptureRatio"   bson:"upCaptureRatio"`
+	DownCaptureRatio float64 `json:"downCaptureRatio" bson:"downCaptureRatio"`
+	BattingAverage   float64 `json:"battingAverage"   bson:"battingAverage"`
+}
+
+// NewBenchmarkMetrics compares portfolio to benchmark. The values must be date aligned.
+func NewBenchmarkMetrics(portfolio, benchmark []float64, periodsPerYear float64) BenchmarkMetrics {
+	if len(portfolio) < 2 {
+		return BenchmarkMetrics{}
+	}
+	excess := make([]float64, len(portfolio))
+	for i := range excess {
+		excess[i] = portfolio[i] - benchmark[i]
+	}
+	return BenchmarkMetrics{
+		ExcessReturn:     finite(calculate.AnnualizedTimeWeightedReturn(portfolio, periodsPerYear) - calculate.AnnualizedTimeWeightedReturn(benchmark, periodsPerYear)),
+		TrackingError:    finite(calculate.TrackingError(excess, periodsPerYear)),
+		InformationRatio: finite(calculate.InformationRatio(portfolio, benchmark, periodsPerYear)),
+		Beta:             finite(calculate.BetaToBenchmark(portfolio, benchmark)),
+		UpCaptureRatio:   finite(calculate.UpCaptureRatio(portfolio, benchmark)),
+		DownCaptureRatio: finite(calculate.DownCaptureRatio(portfolio, benchmark)),
+		BattingAverage:   finite(calculate.BattingAverage(portfolio, benchmark)),
+	}
+}
+
+// NewReport calculates the metrics for result. The benchmark and riskFree lists are optional;
+// when riskFree is empty the risk-free return is zero. Returns are only used on days in every provided list.
+func NewReport(result Result, benchmark, riskFree returns.List) Report {
+	lists := []returns.List{result.Returns(), result.DailyRebalancedReturns()}
+	benchmarkColumn, riskFreeColumn := -1, -1
+	if len(benchmark) > 0 {
+		benchmarkColumn = len(lists)
+		lists = append(lists, benchmark)
+	}
+	if len(riskFree) > 0 {
+		riskFreeColumn = len(lists)
+		lists = append(lists, riskFree)
+	}
+	table := returns.NewTable(lists)
+
+	return Report{
+		Portfolio:       newReportColumn(table, PortfolioReturnsColumn, benchmarkColumn, riskFreeColumn),
+		DailyRebalanced: newReportColumn(table, DailyRebalancedReturnsColumn, benchmarkColumn, riskFreeColumn),
+	}
+}
+
+func newReportColumn(table returns.Table, column, benchmarkColumn, riskFreeColumn int) ReportColumn {
+	var result ReportColumn
+	if table.NumberOfRows() == 0 {
+		return result
+	}
+	periodsPerYear := table.Frequency().PeriodsPerYear()
+	result.Overall = newMetrics("Overall", table, column, benchmarkColumn, riskFreeColumn, periodsPerYear)
+
+	last, first := table.LastTime(), table.FirstTime()
+	for year := last.Year(); year >= first.Year(); year-- {
+		yearEnd := time.Date(year, time.December, 31, 23, 59, 59, 0, last.Location())
+		yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, last.Location())
+		result.Years = append(result.Years, newMetrics(
+			strconv.Itoa(year),
+			table.Between(yearEnd, yearStart), column, benchmarkColumn, riskFreeColumn, periodsPerYear,
+		))
+	}
+
+	for _, years := range TrailingYears {
+		start := last.AddDate(-years, 0, 0)
+		if start.Before(first) {
+			break
+		}
+		result.Trailing = append(result.Trailing, newMetrics(
+			strconv.Itoa(years)+"Y",
+			table.Between(last, start.AddDate(0, 0, 1)), column, benchmarkColumn, riskFreeColumn, periodsPerYear,
+		))
+	}
+	return result
+}
+
+func newMetrics(label string, table returns.Table, column, benchmarkColumn, riskFreeColumn int, periodsPerYear float64) Metrics {
+	metrics := Metrics{
+		Label: label,
+		Start: table.FirstTime(),
+		End:   table.LastTime(),
+	}
+	values := table.ColumnValues()
+	if table.NumberOfRows() < 2 {
+		return metrics
+	}
+	portfolio := values[column]
+	riskFree := make([]float64, len(portfolio))
+	if riskFreeColumn >= 0 {
+		riskFree = values[riskFreeColumn]
+	}
+
+	riskFreeReturn := calculate.AnnualizedArithmeticReturn(riskFree, periodsPerYear)
+	maxDrawdown, _ := calculate.MaxDrawdown(portfolio)
+
+	metrics.TotalReturn = calculate.TimeWeightedReturn(portfolio)
+	metrics.AnnualizedReturn = calculate.AnnualizedTimeWeightedReturn(portfolio, periodsPerYear)
+	metrics.AnnualizedArithmeticReturn = calculate.AnnualizedArithmeticReturn(portfolio, periodsPerYear)
+	metrics.AnnualizedRisk = calculate.AnnualizeRisk(calculate.RiskFromStdDev(portfolio), periodsPerYear)
+	metrics.DownsideRisk = finite(calculate.DownsideVolatility(portfolio, periodsPerYear))
+	metrics.SharpeRatio = finite((metrics.AnnualizedArithmeticReturn - riskFreeReturn) / metrics.AnnualizedRisk)
+	metrics.SortinoRatio = finite(calculate.SortinoRatio(portfolio, riskFree, metrics.DownsideRisk, periodsPerYear))
+	metrics.MaxDrawdown = maxDrawdown
+	metrics.CalmarRatio = finite(calculate.CalmarRatio(portfolio, riskFree, maxDrawdown, periodsPerYear))
+	metrics.UlcerIndex = finite(calculate.UlcerIndex(portfolio, periodsPerYear))
+	metrics.ValueAtRisk = finite(calculate.ValueAtRisk(portfolio, 1, ValueAtRiskConfidenceLevel, periodsPerYear))
+
+	if benchmarkColumn >= 0 {
+		metrics.BenchmarkMetrics = NewBenchmarkMetrics(portfolio, values[benchmarkColumn], periodsPerYear)
+	}
+	return metrics
+}
+
+// finite replaces NaN and infinite values with zero so the metrics may be encoded as JSON.
+func finite(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package backtest_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestNewReport(t *testing.T) {
+	var portfolio, rebalanced, riskFree returns.List
+	for tm := date("2023-06-30"); !tm.Before(date("2020-01-01")); tm = tm.AddDate(0, 0, -1) {
+		if tm.Weekday() == time.Saturday || tm.Weekday() == time.Sunday {
+			continue
+		}
+		value := 0.01
+		if tm.Day()%2 == 0 {
+			value = -0.008
+		}
+		portfolio = append(portfolio, returns.New(tm, value))
+		rebalanced = append(rebalanced, returns.New(tm, value/2))
+		riskFree = append(riskFree, returns.New(tm, 0.0001))
+	}
+	result := backtest.Result{ReturnsTable: returns.NewTable([]returns.List{portfolio, rebalanced})}
+
+	t.Run("without benchmark", func(t *testing.T) {
+		report := backtest.NewReport(result, nil, nil)
+
+		overall := report.Portfolio.Overall
+		assert.Equal(t, "Overall", overall.Label)
+		assert.Equal(t, date("2020-01-01"), overall.Start)
+		assert.Equal(t, date("2023-06-30"), overall.End)
+		assert.InDelta(t, calculate.TimeWeightedReturn(portfolio.Values()), overall.TotalReturn, 1e-9)
+		assert.InDelta(t, overall.AnnualizedArithmeticReturn/overall.AnnualizedRisk, overall.SharpeRatio, 1e-9)
+		assert.Greater(t, overall.MaxDrawdown, 0.0)
+		assert.Less(t, overall.ValueAtRisk, 0.0)
+		assert.Zero(t, overall.Beta)
+		assert.Zero(t, overall.TrackingError)
+
+		require.Len(t, report.Portfolio.Years, 4)
+		assert.Equal(t, "2023", report.Portfolio.Years[0].Label)
+		assert.Equal(t, date("2023-01-02"), report.Portfolio.Years[0].Start)
+		assert.Equal(t, "2020", report.Portfolio.Years[3].Label)
+		assert.Equal(t, date("2020-12-31"), report.Portfolio.Years[3].End)
+
+		require.Len(t, report.Portfolio.Trailing, 2)
+		assert.Equal(t, "1Y", report.Portfolio.Trailing[0].Label)
+		assert.Equal(t, date("2022-07-01"), report.Portfolio.Trailing[0].Start)
+		assert.Equal(t, "3Y", report.Portfolio.Trailing[1].Label)
+
+		assert.InDelta(t, overall.AnnualizedRisk/2, report.DailyRebalanced.Overall.AnnualizedRisk, 1e-9)
+	})
+
+	t.Run("with benchmark and risk-free", func(t *testing.T) {
+		report := backtest.NewReport(result, rebalanced, riskFree)
+
+		overall := report.Portfolio.Overall
+		assert.InDelta(t, 2, overall.Beta, 1e-9)
+		assert.Greater(t, overall.TrackingError, 0.0)
+		riskFreeReturn := calculate.AnnualizedArithmeticReturn(riskFree.Values(), calculate.PeriodsPerYear)
+		assert.InDelta(t, (overall.AnnualizedArithmeticReturn-riskFreeReturn)/overall.AnnualizedRisk, overall.SharpeRatio, 1e-9)
+
+		assert.Greater(t, overall.UpCaptureRatio, 1.0)
+		assert.Greater(t, overall.DownCaptureRatio, 1.0)
+		assert.Greater(t, overall.BattingAverage, 0.0)
+
+		assert.InDelta(t, 1, report.DailyRebalanced.Overall.Beta, 1e-9)
+		assert.InDelta(t, 1, report.DailyRebalanced.Overall.UpCaptureRatio, 1e-9)
+		assert.Zero(t, report.DailyRebalanced.Overall.InformationRatio, "undefined ratios are zero")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		report := backtest.NewReport(result, rebalanced, riskFree)
+		buf, err := json.Marshal(report)
+		require.NoError(t, err)
+		var decoded backtest.Report
+		require.NoError(t, json.Unmarshal(buf, &decoded))
+		assert.Equal(t, report, decoded)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		report := backtest.NewReport(backtest.Result{}, nil, nil)
+		assert.Zero(t, report.Portfolio.Overall)
+		assert.Empty(t, report.Portfolio.Years)
+	})
+}
@@ -1,6 +1,7 @@
 package backtest
 
 import (
+	"slices"
 	"time"
 
 	"github.com/portfoliotree/portfolio/returns"
@@ -9,6 +10,9 @@ import (
 const (
 	PortfolioReturnsColumn       = 0
 	DailyRebalancedReturnsColumn = 1
+
+	// TransactionCostsColumn is only in Result.ReturnsTable when a CostModel is provided (see WithCostModel).
+	TransactionCostsColumn = 2
 )
 
 type Result struct {
@@ -17,6 +21,17 @@ type Result struct {
 	FinalPolicyWeights []float64     `json:"policyWeights"      bson:"policyWeights"`
 	RebalanceTimes     []time.Time   `json:"rebalanceDates"     bson:"rebalanceDates"`
 	PolicyUpdateTimes  []time.Time   `json:"policyUpdatesDates" bson:"policyUpdatesDates"`
+	Trades             []Trade       `json:"trades"             bson:"trades"`
+
+	// GrossExposures and NetExposures are the sum of the absolute asset weights and the sum of
+	// the asset weights (as fractions of portfolio value) at the end of each day. See WithLeverage.
+	GrossExposures []float64 `json:"grossExposures" bson:"grossExposures"`
+	NetExposures   []float64 `json:"netExposures"   bson:"netExposures"`
+
+	// TotalTurnover is the sum of the one-way turnover of each rebalance.
+	TotalTurnover float64 `json:"totalTurnover" bson:"totalTurnover"`
+	// TotalCost is the sum of the daily transaction costs (as fractions of portfolio value).
+	TotalCost float64 `json:"totalCost" bson:"totalCost"`
 }
 
 func (result Result) Returns() returns.List {
@@ -26,3 +41,27 @@ func (result Result) Returns() returns.List {
 func (result Result) DailyRebalancedReturns() returns.List {
 	return result.ReturnsTable.List(DailyRebalancedReturnsColumn)
 }
+
+// TransactionCosts returns the fraction of portfolio value spent on trading each day.
+// It is zero on days without a rebalance and empty when no CostModel was provided.
+func (result Result) TransactionCosts() returns.List {
+	if result.ReturnsTable.NumberOfColumns() <= TransactionCostsColumn {
+		return make(returns.List, 0)
+	}
+	return result.ReturnsTable.List(TransactionCostsColumn)
+}
+
+// ValueWeights returns the asset weights at the end of the day at index as fractions of portfolio value.
+// Weights are stored drifted, so the weights of a portfolio without leverage sum to one plus the return of the day.
+func (result Result) ValueWeights(index int) []float64 {
+	netExposure := 1.0
+	if index < len(result.NetExposures) {
+		netExposure = result.NetExposures[index]
+	}
+	weights := slices.Clone(result.Weights[index])
+	scale := valueFractionScale(weights, netExposure)
+	for i := range weights {
+		weights[i] *= scale
+	}
+	return weights
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"slices"
 	"time"
 
@@ -18,6 +19,9 @@ type (
 	}
 	WindowFunc  func(today time.Time, table returns.Table) returns.Table
 	TriggerFunc func(t time.Time, currentWeights []float64) bool
+
+	// DriftFunc is called with the drifted portfolio weights and the current policy weights.
+	DriftFunc func(currentWeights, policyWeights []float64) bool
 )
 
 type TimeSubtracter interface {
@@ -25,13 +29,16 @@ type TimeSubtracter interface {
 }
 
 // Run runs a portfolio back-test. It calls function parameters for policy updates and to check
-// when a policy update or rebalancing is required.
+// when a policy update or rebalancing is required. See Option for optional behavior.
 func Run(ctx context.Context, end, start time.Time, assetReturns returns.Table,
 	alg PolicyWeightCalculator,
 	lookback TimeSubtracter,
 	shouldCalculatePolicy,
 	shouldRebalanceAssetWeights TriggerFunc,
+	opts ...Option,
 ) (Result, error) {
+	config := newOptions(opts)
+
 	if assetReturns.NumberOfColumns() == 0 {
 		return Result{}, errors.New("no asset returns provided")
 	}
@@ -48,12 +55,23 @@ func Run(ctx context.Context, end, start time.Time, assetReturns returns.Table,
 
 	start = firstPolicyDate
 
+	scaleWeights := scaleToUnitRange
+	if config.leverage {
+		scaleWeights = func([]float64) {}
+	}
+	scaleWeights(policyWeights)
+	if err := config.checkPolicyWeights(policyWeights); err != nil {
+		return Result{}, err
+	}
+
 	var (
 		updatedWeights      = slices.Clone(policyWeights)
 		updatedDailyWeights = slices.Clone(policyWeights)
 
 		backTestReturns,
-		dailyRebalancedReturns []float64
+		dailyRebalancedReturns,
+		transactionCosts []float64
+		preTradeWeights        = make([]float64, assetReturns.NumberOfColumns())
 		historicReturns        returns.Table
 		assetValues            = make([][]float64, assetReturns.NumberOfColumns())
 		assetReturnValuesToday = make([]float64, assetReturns.NumberOfColumns())
@@ -69,7 +87,9 @@ func Run(ctx context.Context, end, start time.Time, assetReturns returns.Table,
 			Weights:            make([][]float64, 0, assetReturns.NumberOfRows()),
 			RebalanceTimes:     make([]time.Time, 0, assetReturns.NumberOfRows()),
 			PolicyUpdateTimes:  make([]time.Time, 0, assetReturns.NumberOfRows()),
-			FinalPolicyWeights: make([]float64, assetReturns.NumberOfColumns()),
+			FinalPolicyWeights: slices.Clone(policyWeights),
+			GrossExposures:     make([]float64, 0, assetReturns.NumberOfRows()),
+			NetExposures:       make([]float64, 0, assetReturns.NumberOfRows()),
 		}
 
 		backTestTimes = make([]time.Time, 0, assetReturns.NumberOfRows())
@@ -78,12 +98,13 @@ func Run(ctx context.Context, end, start time.Time, assetReturns returns.Table,
 
 	for today, i := start, 0; hasNext && !today.After(end) && i < assetReturns.NumberOfRows(); today, i = next, i+1 {
 		next, hasNext = assetReturns.TimeAfter(today)
-		scaleToUnitRange(updatedWeights)
-		scaleToUnitRange(updatedDailyWeights)
+		scaleWeights(updatedWeights)
+		scaleWeights(updatedDailyWeights)
 
 		historicReturns = lookBackWindow(assetValues, lookback, today, assetReturns)
 		assetReturnValuesToday = mostRecentValues(assetReturnValuesToday, historicReturns)
 
+		policyUpdatedToday := false
 		if shouldCalculatePolicy(today, updatedDailyWeights) && start != today {
 			copy(currentWeightsPolicyWeightsInput, updatedWeights)
 			pw, err := alg.PolicyWeights(ctx, today, historicReturns, currentWeightsPolicyWeightsInput)
@@ -92,49 +113,104 @@ func Run(ctx context.Context, end, start time.Time, assetReturns returns.Table,
 			}
 			copy(policyWeights, pw)
 
-			scaleToUnitRange(policyWeights)
+			scaleWeights(policyWeights)
+			if err := config.checkPolicyWeights(policyWeights); err != nil {
+				return Result{}, err
+			}
 
 			recalculatePolicyCount++
 			result.PolicyUpdateTimes = append(result.PolicyUpdateTimes, today)
 			copy(result.FinalPolicyWeights, policyWeights)
+			policyUpdatedToday = true
 		}
 
 		backTestTimes = append(backTestTimes, today)
-		backTestReturns = append(backTestReturns, floats.Dot(updatedWeights, assetReturnValuesToday))
-		dailyRebalancedReturns = append(dailyRebalancedReturns, floats.Dot(updatedDailyWeights, assetReturnValuesToday))
+		cashReturn := 0.0
+		if config.leverage {
+			cashReturn = config.financingReturn(today)
+		}
+		backTestReturns = append(backTestReturns, portfolioReturn(updatedWeights, assetReturnValuesToday, cashReturn))
+		dailyRebalancedReturns = append(dailyRebalancedReturns, portfolioReturn(updatedDailyWeights, assetReturnValuesToday, cashReturn))
+		transactionCosts = append(transactionCosts, 0)
 
 		// calculate drift
 		for j := 0; historicReturns.NumberOfRows() > 0 && j < assetReturns.NumberOfColumns(); j++ {
 			updatedWeights[j] *= 1 + assetReturnValuesToday[j]      // drift
 			updatedDailyWeights[j] *= 1 + assetReturnValuesToday[j] // drift
 		}
+		if config.leverage {
+			// keep weights as fractions of portfolio value
+			scaleByGrowth(updatedWeights, backTestReturns[len(backTestReturns)-1])
+			scaleByGrowth(updatedDailyWeights, dailyRebalancedReturns[len(dailyRebalancedReturns)-1])
+		}
+
+		copy(preTradeWeights, updatedWeights)
+		scaleWeights(preTradeWeights)
+
+		shouldRebalance := shouldRebalanceAssetWeights(today, updatedDailyWeights)
+		if shouldRebalance && config.driftExceeded != nil {
+			shouldRebalance = config.driftExceeded(preTradeWeights, policyWeights)
+		}
+
+		if shouldRebalance {
+			reason := TradeReasonInterval
+			switch {
+			case policyUpdatedToday:
+				reason = TradeReasonPolicyUpdate
+			case config.driftExceeded != nil:
+				reason = TradeReasonThreshold
+			}
+			trade := newTrade(today, reason, true, preTradeWeights, policyWeights)
+			result.Trades = append(result.Trades, trade)
+
+			result.TotalTurnover += trade.Turnover
+			if config.costModel != nil {
+				cost := config.costModel.TransactionCost(today, preTradeWeights, policyWeights)
+				last := len(backTestReturns) - 1
+				backTestReturns[last] = (1+backTestReturns[last])*(1-cost) - 1
+				transactionCosts[last] = cost
+				result.TotalCost += cost
+			}
 
-		if shouldRebalanceAssetWeights(today, updatedDailyWeights) {
 			copy(updatedWeights, policyWeights)
 			rebalanceCount++
 			result.RebalanceTimes = append(result.RebalanceTimes, today)
+		} else if policyUpdatedToday {
+			result.Trades = append(result.Trades, newTrade(today, TradeReasonPolicyUpdate, false, preTradeWeights, policyWeights))
 		}
 
 		ws := slices.Clip(weights[i*assetReturns.NumberOfColumns() : (i+1)*assetReturns.NumberOfColumns()])
 		copy(ws, updatedWeights)
 		result.Weights = append(result.Weights, ws)
 
+		gross, net := exposures(updatedWeights, config.leverage)
+		result.GrossExposures = append(result.GrossExposures, gross)
+		result.NetExposures = append(result.NetExposures, net)
+
 		copy(updatedDailyWeights, policyWeights)
 	}
 
 	slices.Reverse(backTestTimes)
 	slices.Reverse(backTestReturns)
 	slices.Reverse(dailyRebalancedReturns)
+	slices.Reverse(transactionCosts)
 	slices.Reverse(result.Weights)
 	result.Weights = slices.Clip(result.Weights)
+	slices.Reverse(result.GrossExposures)
+	slices.Reverse(result.NetExposures)
 	slices.Reverse(result.RebalanceTimes)
 	result.RebalanceTimes = slices.Clip(result.RebalanceTimes)
 	slices.Reverse(result.PolicyUpdateTimes)
 	result.PolicyUpdateTimes = slices.Clip(result.PolicyUpdateTimes)
-	result.ReturnsTable = returns.NewTableFromValues(backTestTimes, [][]float64{
+	slices.Reverse(result.Trades)
+	columns := [][]float64{
 		backTestReturns,
 		dailyRebalancedReturns,
-	})
+	}
+	if config.costModel != nil {
+		columns = append(columns, transactionCosts)
+	}
+	result.ReturnsTable = returns.NewTableFromValues(backTestTimes, columns)
 
 	return result, nil
 }
@@ -215,14 +291,42 @@ func fetchPolicy(ctx context.Context, end, start time.Time, alg PolicyWeightCalc
 			return time.Time{}, nil, fmt.Errorf("expected policy to have %d weights but got %d", assetReturns.NumberOfColumns(), len(policyWeights))
 		}
 
-		scaleToUnitRange(policyWeights)
-
 		return today, policyWeights, nil
 	}
 
 	return time.Time{}, nil, ErrorNotEnoughData{}
 }
 
+// portfolioReturn adds the return on cash held (or borrowed) to the weighted asset returns.
+// Cash is the remainder after the asset weights so it is zero when the weights sum to one.
+func portfolioReturn(weights, assetReturns []float64, cashReturn float64) float64 {
+	r := floats.Dot(weights, assetReturns)
+	if cashReturn != 0 {
+		r += (1 - floats.Sum(weights)) * cashReturn
+	}
+	return r
+}
+
+func scaleByGrowth(weights []float64, r float64) {
+	if r == -1 {
+		return
+	}
+	floats.Scale(1/(1+r), weights)
+}
+
+// exposures returns the gross (sum of absolute weights) and net (sum of weights) exposure
+// as fractions of portfolio value. Unless leverage is true, weights are scaled to sum to one first.
+func exposures(weights []float64, leverage bool) (gross, net float64) {
+	for _, w := range weights {
+		gross += math.Abs(w)
+		net += w
+	}
+	if !leverage && net != 0 {
+		gross, net = gross/net, 1
+	}
+	return gross, net
+}
+
 func scaleToUnitRange(list []float64) {
 	sum := 0.0
 	for _, v := range list {
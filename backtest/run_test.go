@@ -585,3 +585,24 @@ func (function allocationFunction) PolicyWeights(ctx context.Context, today time
 func testAlgorithm() allocation.Algorithm {
 	return new(allocation.EqualWeights)
 }
+
+func TestRun_FinalPolicyWeights(t *testing.T) {
+	assets := returns.NewTable([]returns.List{
+		{{Time: date("2021-01-06"), Value: 0.01}, {Time: date("2021-01-05"), Value: 0.02}, {Time: date("2021-01-04"), Value: -0.01}},
+		{{Time: date("2021-01-06"), Value: -0.01}, {Time: date("2021-01-05"), Value: 0.01}, {Time: date("2021-01-04"), Value: 0.02}},
+	})
+	end, start, _ := assets.EndAndStartDates()
+	alg := allocationFunction(func(_ context.Context, _ time.Time, _ returns.Table, ws []float64) ([]float64, error) {
+		copy(ws, []float64{3, 1})
+		return ws, nil
+	})
+
+	result, err := backtest.Run(context.Background(), end, start, assets, alg,
+		backtestconfig.WindowNotSet,
+		backtestconfig.Never(),
+		backtestconfig.Never(),
+	)
+	require.NoError(t, err)
+	assert.Empty(t, result.PolicyUpdateTimes)
+	assert.Equal(t, []float64{0.75, 0.25}, result.FinalPolicyWeights, "the initial policy weights are final when the policy is not updated")
+}
@@ -0,0 +1,44 @@
+package backtest
+
+import (
+	"slices"
+	"time"
+)
+
+type TradeReason string
+
+const (
+	TradeReasonInterval     TradeReason = "Interval"
+	TradeReasonThreshold    TradeReason = "Threshold"
+	TradeReasonPolicyUpdate TradeReason = "Policy Update"
+)
+
+// Trade records the weights before and after a rebalance or policy update.
+// Sizes are the differences between TargetWeights and PreTradeWeights.
+// A policy update that does not coincide with a rebalance is recorded with
+// Executed set to false; the trades are only made at the next rebalance.
+type Trade struct {
+	Time            time.Time   `json:"time"            bson:"time"`
+	Reason          TradeReason `json:"reason"          bson:"reason"`
+	Executed        bool        `json:"executed"        bson:"executed"`
+	PreTradeWeights []float64   `json:"preTradeWeights" bson:"preTradeWeights"`
+	TargetWeights   []float64   `json:"targetWeights"   bson:"targetWeights"`
+	Sizes           []float64   `json:"sizes"           bson:"sizes"`
+	Turnover        float64     `json:"turnover"        bson:"turnover"`
+}
+
+func newTrade(today time.Time, reason TradeReason, executed bool, preTradeWeights, targetWeights []float64) Trade {
+	sizes := make([]float64, len(targetWeights))
+	for i := range sizes {
+		sizes[i] = targetWeights[i] - preTradeWeights[i]
+	}
+	return Trade{
+		Time:            today,
+		Reason:          reason,
+		Executed:        executed,
+		PreTradeWeights: slices.Clone(preTradeWeights),
+		TargetWeights:   slices.Clone(targetWeights),
+		Sizes:           sizes,
+		Turnover:        Turnover(preTradeWeights, targetWeights),
+	}
+}
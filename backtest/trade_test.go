@@ -0,0 +1,126 @@
+package backtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestRun_trades(t *testing.T) {
+	assets := returns.NewTable([]returns.List{
+		{
+			{Time: date("2021-01-03"), Value: 0},
+			{Time: date("2021-01-02"), Value: 0.1},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+		{
+			{Time: date("2021-01-03"), Value: 0},
+			{Time: date("2021-01-02"), Value: -0.1},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+	})
+	end, start, _ := assets.EndAndStartDates()
+
+	tiltedAfterFirstDay := allocationFunction(func(_ context.Context, today time.Time, _ returns.Table, ws []float64) ([]float64, error) {
+		if today.Equal(start) {
+			copy(ws, []float64{0.5, 0.5})
+		} else {
+			copy(ws, []float64{0.7, 0.3})
+		}
+		return ws, nil
+	})
+
+	t.Run("scheduled rebalancing", func(t *testing.T) {
+		result, err := backtest.Run(context.Background(), end, start, assets, testAlgorithm(),
+			backtestconfig.WindowNotSet,
+			backtestconfig.Never(),
+			backtestconfig.Daily(),
+		)
+		require.NoError(t, err)
+
+		require.Len(t, result.Trades, 3)
+		trade := result.Trades[1]
+		assert.Equal(t, date("2021-01-02"), trade.Time)
+		assert.Equal(t, backtest.TradeReasonInterval, trade.Reason)
+		assert.True(t, trade.Executed)
+		assert.InDeltaSlice(t, []float64{0.55, 0.45}, trade.PreTradeWeights, 1e-9)
+		assert.InDeltaSlice(t, []float64{0.5, 0.5}, trade.TargetWeights, 1e-9)
+		assert.InDeltaSlice(t, []float64{-0.05, 0.05}, trade.Sizes, 1e-9)
+		assert.InDelta(t, 0.05, trade.Turnover, 1e-9)
+	})
+
+	t.Run("policy updates without rebalancing", func(t *testing.T) {
+		result, err := backtest.Run(context.Background(), end, start, assets, tiltedAfterFirstDay,
+			backtestconfig.WindowNotSet,
+			backtestconfig.Daily(),
+			backtestconfig.Never(),
+		)
+		require.NoError(t, err)
+
+		require.Len(t, result.Trades, 2)
+		for _, trade := range result.Trades {
+			assert.Equal(t, backtest.TradeReasonPolicyUpdate, trade.Reason)
+			assert.False(t, trade.Executed)
+		}
+		assert.Equal(t, date("2021-01-03"), result.Trades[0].Time)
+		assert.InDeltaSlice(t, []float64{0.7, 0.3}, result.Trades[0].TargetWeights, 1e-9)
+	})
+
+	t.Run("policy updates with rebalancing", func(t *testing.T) {
+		result, err := backtest.Run(context.Background(), end, start, assets, tiltedAfterFirstDay,
+			backtestconfig.WindowNotSet,
+			backtestconfig.Daily(),
+			backtestconfig.Daily(),
+		)
+		require.NoError(t, err)
+
+		require.Len(t, result.Trades, 3)
+		assert.Equal(t, []backtest.TradeReason{
+			backtest.TradeReasonPolicyUpdate,
+			backtest.TradeReasonPolicyUpdate,
+			backtest.TradeReasonInterval,
+		}, []backtest.TradeReason{
+			result.Trades[0].Reason,
+			result.Trades[1].Reason,
+			result.Trades[2].Reason,
+		})
+		assert.InDelta(t, 0.15, result.Trades[1].Turnover, 1e-9)
+	})
+}
+
+func TestRun_WithDriftTrigger(t *testing.T) {
+	assets := returns.NewTable([]returns.List{
+		{
+			{Time: date("2021-01-04"), Value: 0.2},
+			{Time: date("2021-01-03"), Value: 0.01},
+			{Time: date("2021-01-02"), Value: 0.01},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+		{
+			{Time: date("2021-01-04"), Value: 0},
+			{Time: date("2021-01-03"), Value: 0},
+			{Time: date("2021-01-02"), Value: 0},
+			{Time: date("2021-01-01"), Value: 0},
+		},
+	})
+	end, start, _ := assets.EndAndStartDates()
+
+	result, err := backtest.Run(context.Background(), end, start, assets, testAlgorithm(),
+		backtestconfig.WindowNotSet,
+		backtestconfig.Never(),
+		backtestconfig.Daily(),
+		backtest.WithDriftTrigger(backtestconfig.Threshold{Absolute: 0.02}.Exceeded),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{date("2021-01-04")}, result.RebalanceTimes)
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, backtest.TradeReasonThreshold, result.Trades[0].Reason)
+}
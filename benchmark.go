@@ -0,0 +1,70 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/portfoliotree/portfolio/allocation"
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// BenchmarkComparison compares backtest returns to the returns of Metadata.Benchmark.
+// Only times where both the portfolio and the benchmark have a return are used.
+// Returns and risks are annualized using the frequency of the returns.
+type BenchmarkComparison struct {
+	Benchmark Component `json:"benchmark" bson:"benchmark"`
+	Start     time.Time `json:"start"     bson:"start"`
+	End       time.Time `json:"end"       bson:"end"`
+
+	backtest.BenchmarkMetrics `bson:",inline"`
+}
+
+// BacktestWithBenchmark fetches the asset returns from provider and runs the backtest.
+// When the metadata has a benchmark, it also fetches the benchmark returns and compares them to the backtest.
+// Otherwise, the comparison is nil.
+func (d Document) BacktestWithBenchmark(ctx context.Context, provider ComponentReturnsProvider, alg allocation.Algorithm, opts ...backtest.Option) (backtest.Result, *BenchmarkComparison, error) {
+	assets, err := provider.ComponentReturnsTable(ctx, d.Spec.Assets...)
+	if err != nil {
+		return backtest.Result{}, nil, err
+	}
+	result, err := d.Spec.Backtest(ctx, assets, alg, opts...)
+	if err != nil || d.Metadata.Benchmark.ID == "" {
+		return result, nil, err
+	}
+	comparison, err := d.BenchmarkComparison(ctx, provider, result)
+	if err != nil {
+		return result, nil, err
+	}
+	return result, &comparison, nil
+}
+
+// BenchmarkComparison fetches the returns of Metadata.Benchmark and compares them to the backtest returns.
+func (d Document) BenchmarkComparison(ctx context.Context, provider ComponentReturnsProvider, result backtest.Result) (BenchmarkComparison, error) {
+	if d.Metadata.Benchmark.ID == "" {
+		return BenchmarkComparison{}, errors.New("metadata does not have a benchmark")
+	}
+	benchmark, err := provider.ComponentReturnsList(ctx, d.Metadata.Benchmark)
+	if err != nil {
+		return BenchmarkComparison{}, err
+	}
+	comparison := NewBenchmarkComparison(result.Returns(), benchmark)
+	comparison.Benchmark = d.Metadata.Benchmark
+	return comparison, nil
+}
+
+// NewBenchmarkComparison compares portfolio to benchmark. The Benchmark field is not set.
+func NewBenchmarkComparison(portfolio, benchmark returns.List) BenchmarkComparison {
+	table := returns.NewTable([]returns.List{portfolio, benchmark})
+	comparison := BenchmarkComparison{
+		Start: table.FirstTime(),
+		End:   table.LastTime(),
+	}
+	if table.NumberOfRows() < 2 {
+		return comparison
+	}
+	values := table.ColumnValues()
+	comparison.BenchmarkMetrics = backtest.NewBenchmarkMetrics(values[0], values[1], table.Frequency().PeriodsPerYear())
+	return comparison
+}
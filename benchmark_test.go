@@ -0,0 +1,97 @@
+package portfolio_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestDocument_BacktestWithBenchmark(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+metadata:
+  benchmark: BIGPX
+spec:
+  assets: [ACWI, AGG]
+  policy:
+    weights: [60, 40]
+    weights_algorithm: Constant Weights
+    rebalancing_interval: Quarterly
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	provider := portfoliotest.ComponentReturnsProvider()
+	result, comparison, err := pf.BacktestWithBenchmark(ctx, provider, nil)
+	require.NoError(t, err)
+	require.NotNil(t, comparison)
+
+	assert.Equal(t, "BIGPX", comparison.Benchmark.ID)
+	assert.Equal(t, date("2008-03-31"), comparison.Start)
+	assert.Equal(t, date("2023-06-12"), comparison.End, "BIGPX ends before the assets")
+
+	portfolioReturns := result.Returns().Between(comparison.End, comparison.Start)
+	benchmark, err := provider.ComponentReturnsList(ctx, pf.Metadata.Benchmark)
+	require.NoError(t, err)
+	benchmark = benchmark.Between(comparison.End, comparison.Start)
+	require.Equal(t, portfolioReturns.Times(), benchmark.Times())
+
+	assert.InDelta(t, portfolioReturns.AnnualizedTimeWeightedReturn()-benchmark.AnnualizedTimeWeightedReturn(), comparison.ExcessReturn, 1e-9)
+	assert.InDelta(t, calculate.InformationRatio(portfolioReturns.Values(), benchmark.Values(), calculate.PeriodsPerYear), comparison.InformationRatio, 1e-9)
+	assert.Greater(t, comparison.TrackingError, 0.0)
+	assert.InDelta(t, 1, comparison.Beta, 0.5)
+	assert.Greater(t, comparison.UpCaptureRatio, 0.0)
+	assert.Greater(t, comparison.DownCaptureRatio, 0.0)
+	assert.Greater(t, comparison.BattingAverage, 0.0)
+	assert.Less(t, comparison.BattingAverage, 1.0)
+
+	t.Run("without benchmark", func(t *testing.T) {
+		doc := pf
+		doc.Metadata.Benchmark = portfolio.Component{}
+		_, comparison, err := doc.BacktestWithBenchmark(ctx, provider, nil)
+		require.NoError(t, err)
+		assert.Nil(t, comparison)
+
+		_, err = doc.BenchmarkComparison(ctx, provider, result)
+		assert.ErrorContains(t, err, "metadata does not have a benchmark")
+	})
+}
+
+func TestNewBenchmarkComparison(t *testing.T) {
+	portfolioReturns := returns.List{
+		{Time: date("2021-01-06"), Value: 0.01},
+		{Time: date("2021-01-05"), Value: -0.02},
+		{Time: date("2021-01-04"), Value: 0.03},
+	}
+
+	t.Run("same as benchmark", func(t *testing.T) {
+		comparison := portfolio.NewBenchmarkComparison(portfolioReturns, portfolioReturns)
+		assert.Zero(t, comparison.TrackingError)
+		assert.Zero(t, comparison.InformationRatio, "the tracking error is zero")
+		_, err := json.Marshal(comparison)
+		assert.NoError(t, err)
+	})
+
+	t.Run("constant benchmark", func(t *testing.T) {
+		benchmark := returns.List{
+			{Time: date("2021-01-06"), Value: 0.001},
+			{Time: date("2021-01-05"), Value: 0.001},
+			{Time: date("2021-01-04"), Value: 0.001},
+		}
+		comparison := portfolio.NewBenchmarkComparison(portfolioReturns, benchmark)
+		assert.Zero(t, comparison.Beta, "the benchmark variance is zero")
+		buf, err := json.Marshal(comparison)
+		require.NoError(t, err)
+		assert.Contains(t, string(buf), `"excessReturn":`)
+	})
+}
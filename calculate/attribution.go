@@ -0,0 +1,53 @@
+package calculate
+
+import "math"
+
+// BrinsonEffects are the Brinson-Fachler attribution effects of one segment for one period.
+// Over all segments they sum to the portfolio return minus the benchmark return.
+type BrinsonEffects struct {
+	Allocation  float64 `json:"allocation"  bson:"allocation"`
+	Selection   float64 `json:"selection"   bson:"selection"`
+	Interaction float64 `json:"interaction" bson:"interaction"`
+}
+
+func (effects BrinsonEffects) Total() float64 {
+	return effects.Allocation + effects.Selection + effects.Interaction
+}
+
+// BrinsonFachler returns the effects for each segment given the segment weights and returns of the portfolio
+// and the benchmark. The portfolio weights and the benchmark weights should each sum to one.
+func BrinsonFachler(portfolioWeights, benchmarkWeights, portfolioReturns, benchmarkReturns []float64) []BrinsonEffects {
+	var benchmarkReturn float64
+	for i := range benchmarkWeights {
+		benchmarkReturn += benchmarkWeights[i] * benchmarkReturns[i]
+	}
+	result := make([]BrinsonEffects, len(portfolioWeights))
+	for i := range result {
+		activeWeight := portfolioWeights[i] - benchmarkWeights[i]
+		result[i] = BrinsonEffects{
+			Allocation:  activeWeight * (benchmarkReturns[i] - benchmarkReturn),
+			Selection:   benchmarkWeights[i] * (portfolioReturns[i] - benchmarkReturns[i]),
+			Interaction: activeWeight * (portfolioReturns[i] - benchmarkReturns[i]),
+		}
+	}
+	return result
+}
+
+// CarinoLinkingCoefficients returns the factor to multiply each period's effects by so the sum of the scaled
+// effects equals the compounded portfolio return minus the compounded benchmark return.
+// The coefficients are NaN when a return is -100% or less, so callers should check the returns first.
+func CarinoLinkingCoefficients(portfolioReturns, benchmarkReturns []float64) []float64 {
+	total := carinoCoefficient(productOfReturnValues(portfolioReturns)-1, productOfReturnValues(benchmarkReturns)-1)
+	result := make([]float64, len(portfolioReturns))
+	for i := range result {
+		result[i] = carinoCoefficient(portfolioReturns[i], benchmarkReturns[i]) / total
+	}
+	return result
+}
+
+func carinoCoefficient(portfolioReturn, benchmarkReturn float64) float64 {
+	if portfolioReturn == benchmarkReturn {
+		return 1 / (1 + portfolioReturn)
+	}
+	return (math.Log1p(portfolioReturn) - math.Log1p(benchmarkReturn)) / (portfolioReturn - benchmarkReturn)
+}
@@ -0,0 +1,45 @@
+package calculate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+func TestBrinsonFachler(t *testing.T) {
+	portfolioWeights := []float64{0.7, 0.3}
+	benchmarkWeights := []float64{0.5, 0.5}
+	portfolioReturns := []float64{0.05, 0.01}
+	benchmarkReturns := []float64{0.04, 0.02}
+
+	effects := calculate.BrinsonFachler(portfolioWeights, benchmarkWeights, portfolioReturns, benchmarkReturns)
+	require.Len(t, effects, 2)
+
+	// the benchmark return is 0.03
+	assert.InDelta(t, 0.2*0.01, effects[0].Allocation, 1e-12)
+	assert.InDelta(t, 0.5*0.01, effects[0].Selection, 1e-12)
+	assert.InDelta(t, 0.2*0.01, effects[0].Interaction, 1e-12)
+	assert.InDelta(t, -0.2*-0.01, effects[1].Allocation, 1e-12)
+	assert.InDelta(t, 0.5*-0.01, effects[1].Selection, 1e-12)
+	assert.InDelta(t, -0.2*-0.01, effects[1].Interaction, 1e-12)
+
+	portfolioReturn := 0.7*0.05 + 0.3*0.01
+	assert.InDelta(t, portfolioReturn-0.03, effects[0].Total()+effects[1].Total(), 1e-12)
+}
+
+func TestCarinoLinkingCoefficients(t *testing.T) {
+	portfolio := []float64{0.02, -0.01, 0.03}
+	benchmark := []float64{0.01, -0.01, 0.01}
+
+	coefficients := calculate.CarinoLinkingCoefficients(portfolio, benchmark)
+	require.Len(t, coefficients, 3)
+
+	var linked float64
+	for i := range coefficients {
+		linked += coefficients[i] * (portfolio[i] - benchmark[i])
+	}
+	assert.InDelta(t, calculate.TimeWeightedReturn(portfolio)-calculate.TimeWeightedReturn(benchmark), linked, 1e-12)
+}
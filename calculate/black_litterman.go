@@ -0,0 +1,131 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// DefaultRiskAversion is the market risk aversion coefficient commonly used to imply equilibrium returns.
+	DefaultRiskAversion = 2.5
+	// DefaultBlackLittermanTau scales the uncertainty of the equilibrium returns.
+	DefaultBlackLittermanTau = 0.05
+)
+
+// View is an investor view for the Black-Litterman model.
+// Weights is a row of the pick matrix: for an absolute view on one asset it has a single 1,
+// for a relative view the outperforming assets sum to 1 and the underperforming assets sum to -1.
+// Return is the expected return of the view portfolio. Confidence must be greater than zero
+// and at most one; a confidence of one means the view is certain.
+type View struct {
+	Weights    []float64 `json:"weights"    bson:"weights"`
+	Return     float64   `json:"return"     bson:"return"`
+	Confidence float64   `json:"confidence" bson:"confidence"`
+}
+
+// Validate returns an error when the view is malformed for n assets.
+func (view View) Validate(n int) error {
+	if len(view.Weights) != n {
+		return fmt.Errorf("expected view to have %d weights but got %d", n, len(view.Weights))
+	}
+	if !(view.Confidence > 0 && view.Confidence <= 1) {
+		return fmt.Errorf("view confidence %v must be greater than 0 and at most 1", view.Confidence)
+	}
+	for _, w := range view.Weights {
+		if w != 0 {
+			return nil
+		}
+	}
+	return errors.New("view must include at least one asset")
+}
+
+// EquilibriumReturns returns the returns implied by marketWeights: riskAversion * covariance * marketWeights.
+// The marketWeights should sum to one.
+func EquilibriumReturns(marketWeights, vols []float64, correlations [][]float64, riskAversion float64) []float64 {
+	checkOptimizerInputs(marketWeights, vols, correlations)
+	var implied mat.VecDense
+	implied.MulVec(covarianceMatrix(vols, correlations), mat.NewVecDense(len(marketWeights), marketWeights))
+	implied.ScaleVec(riskAversion, &implied)
+	return implied.RawVector().Data
+}
+
+// BlackLittermanReturns blends the equilibrium returns implied by marketWeights with views.
+// The uncertainty of each view is ((1 - Confidence) / Confidence) times the variance of the view portfolio under tau * covariance.
+// The vols, view returns, and resulting returns must be expressed over the same period (for example annualized).
+func BlackLittermanReturns(marketWeights, vols []float64, correlations [][]float64, riskAversion, tau float64, views []View) ([]float64, error) {
+	n := len(marketWeights)
+	prior := EquilibriumReturns(marketWeights, vols, correlations, riskAversion)
+	if len(views) == 0 {
+		return prior, nil
+	}
+	for i, view := range views {
+		if err := view.Validate(n); err != nil {
+			return nil, fmt.Errorf("view %d: %w", i, err)
+		}
+	}
+
+	var scaledCovariance mat.Dense
+	scaledCovariance.Scale(tau, covarianceMatrix(vols, correlations))
+
+	k := len(views)
+	pick := mat.NewDense(k, n, nil)
+	differences := mat.NewVecDense(k, nil)
+	priorVec := mat.NewVecDense(n, prior)
+	for i, view := range views {
+		row := mat.NewVecDense(n, view.Weights)
+		pick.SetRow(i, view.Weights)
+		differences.SetVec(i, view.Return-mat.Dot(row, priorVec))
+	}
+
+	// viewCovariance = P (tau Σ) Pᵀ + Ω
+	var covariancePickT, viewCovariance mat.Dense
+	covariancePickT.Mul(&scaledCovariance, pick.T())
+	viewCovariance.Mul(pick, &covariancePickT)
+	for i, view := range views {
+		variance := viewCovariance.At(i, i)
+		viewCovariance.Set(i, i, variance+variance*(1-view.Confidence)/view.Confidence)
+	}
+
+	var adjustment mat.VecDense
+	if err := adjustment.SolveVec(&viewCovariance, differences); err != nil {
+		return nil, fmt.Errorf("failed to blend views: %w", err)
+	}
+	var posterior mat.VecDense
+	posterior.MulVec(&covariancePickT, &adjustment)
+	posterior.AddVec(&posterior, priorVec)
+	return posterior.RawVector().Data, nil
+}
+
+// BlackLittermanWeights sets ws to the unconstrained mean-variance weights for the Black-Litterman returns:
+// (riskAversion * covariance)⁻¹ * returns. Without views ws is set to marketWeights.
+// The weights may be negative or sum to more than one when views are strong.
+func BlackLittermanWeights(ws, marketWeights, vols []float64, correlations [][]float64, riskAversion, tau float64, views []View) error {
+	if len(ws) != len(marketWeights) {
+		panic("length of weights and market weights must be equal")
+	}
+	expectedReturns, err := BlackLittermanReturns(marketWeights, vols, correlations, riskAversion, tau, views)
+	if err != nil {
+		return err
+	}
+	var scaledCovariance mat.Dense
+	scaledCovariance.Scale(riskAversion, covarianceMatrix(vols, correlations))
+	var result mat.VecDense
+	if err := result.SolveVec(&scaledCovariance, mat.NewVecDense(len(expectedReturns), expectedReturns)); err != nil {
+		return fmt.Errorf("failed to calculate Black-Litterman weights: %w", err)
+	}
+	copy(ws, result.RawVector().Data)
+	return nil
+}
+
+func covarianceMatrix(vols []float64, correlations [][]float64) *mat.Dense {
+	n := len(vols)
+	covariance := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			covariance.Set(i, j, vols[i]*vols[j]*correlations[i][j])
+		}
+	}
+	return covariance
+}
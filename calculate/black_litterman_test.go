@@ -0,0 +1,72 @@
+package calculate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+func TestEquilibriumReturns(t *testing.T) {
+	implied := calculate.EquilibriumReturns([]float64{0.6, 0.4}, []float64{0.2, 0.05}, [][]float64{{1, 0}, {0, 1}}, 2.5)
+	assert.InDeltaSlice(t, []float64{2.5 * 0.6 * 0.04, 2.5 * 0.4 * 0.0025}, implied, 1e-12)
+}
+
+func TestBlackLittermanReturns(t *testing.T) {
+	marketWeights, vols, correlations := []float64{0.6, 0.4}, []float64{0.2, 0.05}, [][]float64{{1, 0.3}, {0.3, 1}}
+	prior := calculate.EquilibriumReturns(marketWeights, vols, correlations, calculate.DefaultRiskAversion)
+
+	t.Run("certain absolute view", func(t *testing.T) {
+		posterior, err := calculate.BlackLittermanReturns(marketWeights, vols, correlations, calculate.DefaultRiskAversion, calculate.DefaultBlackLittermanTau, []calculate.View{
+			{Weights: []float64{1, 0}, Return: 0.12, Confidence: 1},
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 0.12, posterior[0], 1e-9)
+		assert.Greater(t, posterior[1], prior[1], "correlated assets move with the view")
+	})
+
+	t.Run("confidence moves the posterior toward the view", func(t *testing.T) {
+		view := calculate.View{Weights: []float64{1, -1}, Return: 0.10}
+		view.Confidence = 0.25
+		low, err := calculate.BlackLittermanReturns(marketWeights, vols, correlations, calculate.DefaultRiskAversion, calculate.DefaultBlackLittermanTau, []calculate.View{view})
+		require.NoError(t, err)
+		view.Confidence = 0.75
+		high, err := calculate.BlackLittermanReturns(marketWeights, vols, correlations, calculate.DefaultRiskAversion, calculate.DefaultBlackLittermanTau, []calculate.View{view})
+		require.NoError(t, err)
+
+		priorSpread, lowSpread, highSpread := prior[0]-prior[1], low[0]-low[1], high[0]-high[1]
+		assert.Less(t, priorSpread, lowSpread)
+		assert.Less(t, lowSpread, highSpread)
+		assert.Less(t, highSpread, 0.10)
+	})
+
+	t.Run("invalid view", func(t *testing.T) {
+		_, err := calculate.BlackLittermanReturns(marketWeights, vols, correlations, calculate.DefaultRiskAversion, calculate.DefaultBlackLittermanTau, []calculate.View{
+			{Weights: []float64{1, 0}, Return: 0.12, Confidence: 0},
+		})
+		assert.ErrorContains(t, err, "confidence")
+	})
+}
+
+func TestBlackLittermanWeights(t *testing.T) {
+	marketWeights, vols, correlations := []float64{0.6, 0.4}, []float64{0.2, 0.05}, [][]float64{{1, 0.3}, {0.3, 1}}
+
+	t.Run("no views", func(t *testing.T) {
+		ws := make([]float64, 2)
+		err := calculate.BlackLittermanWeights(ws, marketWeights, vols, correlations, calculate.DefaultRiskAversion, calculate.DefaultBlackLittermanTau, nil)
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, marketWeights, ws, 1e-9)
+	})
+
+	t.Run("bullish view", func(t *testing.T) {
+		ws := make([]float64, 2)
+		err := calculate.BlackLittermanWeights(ws, marketWeights, vols, correlations, calculate.DefaultRiskAversion, calculate.DefaultBlackLittermanTau, []calculate.View{
+			{Weights: []float64{1, -1}, Return: 0.10, Confidence: 0.5},
+		})
+		require.NoError(t, err)
+		assert.Greater(t, ws[0], marketWeights[0])
+		assert.Less(t, ws[1], marketWeights[1])
+	})
+}
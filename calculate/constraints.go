@@ -0,0 +1,180 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// WeightConstraints bound the weights found by the optimizing weight functions.
+// The zero value constrains weights to be between zero and one (long-only).
+type WeightConstraints struct {
+	// Minimum is the lowest weight for each asset. When nil, each minimum is zero.
+	Minimum []float64 `json:"minimum,omitempty" bson:"minimum,omitempty"`
+	// Maximum is the highest weight for each asset. When nil, each maximum is one.
+	Maximum []float64         `json:"maximum,omitempty" bson:"maximum,omitempty"`
+	Groups  []GroupConstraint `json:"groups,omitempty"  bson:"groups,omitempty"`
+}
+
+// GroupConstraint bounds the sum of the weights of the assets at the Assets indexes.
+type GroupConstraint struct {
+	Assets  []int   `json:"assets"  bson:"assets"`
+	Minimum float64 `json:"minimum" bson:"minimum"`
+	Maximum float64 `json:"maximum" bson:"maximum"`
+}
+
+const constraintTolerance = 1e-9
+
+func (constraints WeightConstraints) IsSet() bool {
+	return constraints.Minimum != nil || constraints.Maximum != nil || len(constraints.Groups) > 0
+}
+
+// Validate returns an error when the constraints are malformed for n assets
+// or when no weights summing to one can satisfy them.
+func (constraints WeightConstraints) Validate(n int) error {
+	if n == 0 && !constraints.IsSet() {
+		return nil
+	}
+	if constraints.Minimum != nil && len(constraints.Minimum) != n {
+		return fmt.Errorf("expected %d minimum weights but got %d", n, len(constraints.Minimum))
+	}
+	if constraints.Maximum != nil && len(constraints.Maximum) != n {
+		return fmt.Errorf("expected %d maximum weights but got %d", n, len(constraints.Maximum))
+	}
+	lower, upper := constraints.bounds(n)
+	var list []error
+	sumLower, sumUpper := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		if lower[i] > upper[i] {
+			list = append(list, fmt.Errorf("infeasible weight constraints: asset %d minimum %v is greater than its maximum %v", i, lower[i], upper[i]))
+		}
+		sumLower += lower[i]
+		sumUpper += upper[i]
+	}
+	if sumLower > 1+constraintTolerance {
+		list = append(list, fmt.Errorf("infeasible weight constraints: the minimum weights sum to %v which is more than one", sumLower))
+	}
+	if sumUpper < 1-constraintTolerance {
+		list = append(list, fmt.Errorf("infeasible weight constraints: the maximum weights sum to %v which is less than one", sumUpper))
+	}
+	for gi, group := range constraints.Groups {
+		if len(group.Assets) == 0 {
+			list = append(list, fmt.Errorf("weight constraint group %d has no assets", gi))
+			continue
+		}
+		if group.Minimum > group.Maximum {
+			list = append(list, fmt.Errorf("infeasible weight constraints: group %d minimum %v is greater than its maximum %v", gi, group.Minimum, group.Maximum))
+		}
+		groupLower, groupUpper := 0.0, 0.0
+		for _, ai := range group.Assets {
+			if ai < 0 || ai >= n {
+				list = append(list, fmt.Errorf("weight constraint group %d asset index %d out of range", gi, ai))
+				continue
+			}
+			groupLower += lower[ai]
+			groupUpper += upper[ai]
+		}
+		if groupUpper < group.Minimum-constraintTolerance {
+			list = append(list, fmt.Errorf("infeasible weight constraints: group %d minimum %v is more than the sum of its asset maximums %v", gi, group.Minimum, groupUpper))
+		}
+		if groupLower > group.Maximum+constraintTolerance {
+			list = append(list, fmt.Errorf("infeasible weight constraints: group %d maximum %v is less than the sum of its asset minimums %v", gi, group.Maximum, groupLower))
+		}
+		if group.Maximum+(sumUpper-groupUpper) < 1-constraintTolerance {
+			list = append(list, fmt.Errorf("infeasible weight constraints: group %d maximum %v prevents the weights from summing to one", gi, group.Maximum))
+		}
+		if group.Minimum+(sumLower-groupLower) > 1+constraintTolerance {
+			list = append(list, fmt.Errorf("infeasible weight constraints: group %d minimum %v prevents the weights from summing to one", gi, group.Minimum))
+		}
+	}
+	return errors.Join(list...)
+}
+
+func (constraints WeightConstraints) bounds(n int) (lower, upper []float64) {
+	lower, upper = constraints.Minimum, constraints.Maximum
+	if lower == nil {
+		lower = make([]float64, n)
+	}
+	if upper == nil {
+		upper = make([]float64, n)
+		for i := range upper {
+			upper[i] = 1
+		}
+	}
+	return lower, upper
+}
+
+// groupViolation returns the sum of the amounts each group constraint is exceeded by.
+func (constraints WeightConstraints) groupViolation(ws []float64) float64 {
+	violation := 0.0
+	for _, group := range constraints.Groups {
+		sum := 0.0
+		for _, ai := range group.Assets {
+			sum += ws[ai]
+		}
+		violation += math.Max(0, sum-group.Maximum) + math.Max(0, group.Minimum-sum)
+	}
+	return violation
+}
+
+// project sets ws to weights that sum to one and satisfy the constraints.
+// Box constraints are always satisfied. Group constraints are approached by alternating projections
+// and groupViolation should be used to check the result.
+func (constraints WeightConstraints) project(ws, lower, upper []float64) {
+	const maxIterations = 100
+	for i := 0; i < maxIterations; i++ {
+		projectOntoBoundedSimplex(ws, lower, upper)
+		if constraints.groupViolation(ws) <= constraintTolerance {
+			return
+		}
+		for _, group := range constraints.Groups {
+			sum := 0.0
+			for _, ai := range group.Assets {
+				sum += ws[ai]
+			}
+			var shift float64
+			switch {
+			case sum > group.Maximum:
+				shift = (group.Maximum - sum) / float64(len(group.Assets))
+			case sum < group.Minimum:
+				shift = (group.Minimum - sum) / float64(len(group.Assets))
+			default:
+				continue
+			}
+			for _, ai := range group.Assets {
+				ws[ai] += shift
+			}
+		}
+	}
+	projectOntoBoundedSimplex(ws, lower, upper)
+}
+
+// projectOntoBoundedSimplex sets ws[i] to min(max(ws[i] - shift, lower[i]), upper[i])
+// where shift is found by bisection so that the weights sum to one.
+func projectOntoBoundedSimplex(ws, lower, upper []float64) {
+	const iterations = 100
+	low, high := math.Inf(1), math.Inf(-1)
+	for i := range ws {
+		low = math.Min(low, ws[i]-upper[i])
+		high = math.Max(high, ws[i]-lower[i])
+	}
+	sum := func(shift float64) float64 {
+		s := 0.0
+		for i := range ws {
+			s += math.Min(math.Max(ws[i]-shift, lower[i]), upper[i])
+		}
+		return s
+	}
+	for i := 0; i < iterations; i++ {
+		mid := (low + high) / 2
+		if sum(mid) > 1 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	shift := (low + high) / 2
+	for i := range ws {
+		ws[i] = math.Min(math.Max(ws[i]-shift, lower[i]), upper[i])
+	}
+}
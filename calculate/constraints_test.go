@@ -0,0 +1,111 @@
+package calculate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+func TestWeightConstraints_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		Name                string
+		Constraints         calculate.WeightConstraints
+		ErrorStringContains string
+	}{
+		{Name: "zero value"},
+		{
+			Name:        "feasible",
+			Constraints: calculate.WeightConstraints{Minimum: []float64{0.1, 0, 0}, Maximum: []float64{0.5, 0.5, 0.5}, Groups: []calculate.GroupConstraint{{Assets: []int{0, 1}, Minimum: 0.4, Maximum: 0.7}}},
+		},
+		{
+			Name:                "wrong number of bounds",
+			Constraints:         calculate.WeightConstraints{Minimum: []float64{0.1}},
+			ErrorStringContains: "expected 3 minimum weights",
+		},
+		{
+			Name:                "minimums sum to more than one",
+			Constraints:         calculate.WeightConstraints{Minimum: []float64{0.5, 0.5, 0.5}},
+			ErrorStringContains: "minimum weights sum to 1.5",
+		},
+		{
+			Name:                "maximums sum to less than one",
+			Constraints:         calculate.WeightConstraints{Maximum: []float64{0.2, 0.2, 0.2}},
+			ErrorStringContains: "maximum weights sum to",
+		},
+		{
+			Name:                "asset minimum greater than maximum",
+			Constraints:         calculate.WeightConstraints{Minimum: []float64{0.6, 0, 0}, Maximum: []float64{0.5, 1, 1}},
+			ErrorStringContains: "asset 0 minimum 0.6 is greater than its maximum 0.5",
+		},
+		{
+			Name:                "group minimum above asset maximums",
+			Constraints:         calculate.WeightConstraints{Maximum: []float64{0.2, 0.2, 1}, Groups: []calculate.GroupConstraint{{Assets: []int{0, 1}, Minimum: 0.5, Maximum: 1}}},
+			ErrorStringContains: "group 0 minimum 0.5 is more than the sum of its asset maximums",
+		},
+		{
+			Name:                "group maximum prevents summing to one",
+			Constraints:         calculate.WeightConstraints{Maximum: []float64{1, 1, 0.3}, Groups: []calculate.GroupConstraint{{Assets: []int{0, 1}, Minimum: 0, Maximum: 0.5}}},
+			ErrorStringContains: "prevents the weights from summing to one",
+		},
+		{
+			Name:                "group asset out of range",
+			Constraints:         calculate.WeightConstraints{Groups: []calculate.GroupConstraint{{Assets: []int{3}, Minimum: 0, Maximum: 1}}},
+			ErrorStringContains: "out of range",
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := tt.Constraints.Validate(3)
+			if tt.ErrorStringContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.ErrorStringContains)
+			}
+		})
+	}
+}
+
+func TestMinimumVarianceWeights_constraints(t *testing.T) {
+	t.Run("asset bounds", func(t *testing.T) {
+		ws := []float64{0.5, 0.5}
+		err := calculate.MinimumVarianceWeights(context.Background(), ws, []float64{0.1, 0.2}, [][]float64{{1, 0}, {0, 1}}, calculate.WeightConstraints{
+			Maximum: []float64{0.6, 1},
+		})
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.6, 0.4}, ws, 0.001)
+	})
+	t.Run("group bounds", func(t *testing.T) {
+		ws := []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}
+		err := calculate.MinimumVarianceWeights(context.Background(), ws, []float64{0.1, 0.1, 0.3}, [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}, calculate.WeightConstraints{
+			Groups: []calculate.GroupConstraint{{Assets: []int{0, 1}, Minimum: 0, Maximum: 0.8}},
+		})
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.4, 0.4, 0.2}, ws, 0.001)
+	})
+	t.Run("infeasible", func(t *testing.T) {
+		ws := []float64{0.5, 0.5}
+		err := calculate.MinimumVarianceWeights(context.Background(), ws, []float64{0.1, 0.2}, [][]float64{{1, 0}, {0, 1}}, calculate.WeightConstraints{
+			Maximum: []float64{0.3, 0.3},
+		})
+		assert.ErrorContains(t, err, "infeasible")
+	})
+}
+
+func TestEqualRiskContributionWeights_constraints(t *testing.T) {
+	ws := []float64{0.5, 0.5}
+	err := calculate.EqualRiskContributionWeightsWithConstraints(context.Background(), ws, []float64{0.1, 0.2}, [][]float64{{1, 0}, {0, 1}}, calculate.WeightConstraints{
+		Maximum: []float64{0.6, 1},
+	})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6, 0.4}, ws, 0.001)
+}
+
+func TestEqualRiskContributionWeights(t *testing.T) {
+	ws := []float64{0.5, 0.5}
+	err := calculate.EqualRiskContributionWeights(context.Background(), ws, []float64{0.1, 0.2}, [][]float64{{1, 0}, {0, 1}})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{2.0 / 3, 1.0 / 3}, ws, 0.001)
+}
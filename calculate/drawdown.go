@@ -0,0 +1,82 @@
+package calculate
+
+import "slices"
+
+// Drawdown is a decline in the growth of returns from a peak and the recovery back to that peak.
+// The fields are indexes into the newest-first returns passed to Drawdowns.
+type Drawdown struct {
+	// Peak is the index of the return that set the peak. It is len(values) when the peak is the starting value.
+	Peak int `json:"peak" bson:"peak"`
+	// Trough is the index of the return with the lowest growth in the drawdown.
+	Trough int `json:"trough" bson:"trough"`
+	// Recovery is the index of the return that brought growth back to the peak. It is -1 when the drawdown is ongoing.
+	Recovery int `json:"recovery" bson:"recovery"`
+	// Depth is the decline from the peak to the trough as a positive fraction of the peak.
+	Depth float64 `json:"depth" bson:"depth"`
+}
+
+func (drawdown Drawdown) Ongoing() bool { return drawdown.Recovery < 0 }
+
+// Length is the number of returns after the peak up to the recovery or the newest return when the drawdown is ongoing.
+func (drawdown Drawdown) Length() int {
+	if drawdown.Ongoing() {
+		return drawdown.Peak
+	}
+	return drawdown.Peak - drawdown.Recovery
+}
+
+// TimeToRecover is the number of returns after the trough up to the recovery. It is zero when the drawdown is ongoing.
+func (drawdown Drawdown) TimeToRecover() int {
+	if drawdown.Ongoing() {
+		return 0
+	}
+	return drawdown.Trough - drawdown.Recovery
+}
+
+// Underwater returns the decline of the growth of values from the running peak (zero or negative).
+// The growth starts at one before the oldest value.
+func Underwater(values []float64) []float64 {
+	result := make([]float64, len(values))
+	growth, peak := 1.0, 1.0
+	for i := len(values) - 1; i >= 0; i-- {
+		growth *= 1 + values[i]
+		if growth >= peak {
+			peak = growth
+			continue
+		}
+		result[i] = growth/peak - 1
+	}
+	return result
+}
+
+// Drawdowns returns every drawdown in values sorted newest first.
+func Drawdowns(values []float64) []Drawdown {
+	var (
+		result     []Drawdown
+		current    *Drawdown
+		peak       = len(values)
+		underwater = Underwater(values)
+	)
+	for index := len(values) - 1; index >= 0; index-- {
+		if underwater[index] == 0 {
+			if current != nil {
+				current.Recovery = index
+				result = append(result, *current)
+				current = nil
+			}
+			peak = index
+			continue
+		}
+		if current == nil {
+			current = &Drawdown{Peak: peak, Recovery: -1}
+		}
+		if -underwater[index] > current.Depth {
+			current.Depth, current.Trough = -underwater[index], index
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	slices.Reverse(result)
+	return result
+}
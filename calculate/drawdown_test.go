@@ -0,0 +1,66 @@
+package calculate
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawdowns(t *testing.T) {
+	t.Run("recovered and ongoing", func(t *testing.T) {
+		// oldest to newest: 0.1, -0.1, -0.1, 0.25, -0.5
+		values := []float64{-0.5, 0.25, -0.1, -0.1, 0.1}
+
+		assert.InDeltaSlice(t, []float64{-0.5, 0, -0.19, -0.1, 0}, Underwater(values), 1e-9)
+
+		drawdowns := Drawdowns(values)
+		require.Len(t, drawdowns, 2)
+
+		ongoing := drawdowns[0]
+		assert.Equal(t, 1, ongoing.Peak)
+		assert.Equal(t, 0, ongoing.Trough)
+		assert.True(t, ongoing.Ongoing())
+		assert.InDelta(t, 0.5, ongoing.Depth, 1e-9)
+		assert.Equal(t, 1, ongoing.Length())
+		assert.Zero(t, ongoing.TimeToRecover())
+
+		recovered := drawdowns[1]
+		assert.Equal(t, 4, recovered.Peak)
+		assert.Equal(t, 2, recovered.Trough)
+		assert.Equal(t, 1, recovered.Recovery)
+		assert.InDelta(t, 0.19, recovered.Depth, 1e-9)
+		assert.Equal(t, 3, recovered.Length())
+		assert.Equal(t, 1, recovered.TimeToRecover())
+	})
+
+	t.Run("starts with a loss", func(t *testing.T) {
+		drawdowns := Drawdowns([]float64{0.1, -0.1})
+		require.Len(t, drawdowns, 1)
+		assert.Equal(t, 2, drawdowns[0].Peak, "the peak is the starting value")
+		assert.Equal(t, 1, drawdowns[0].Trough)
+		assert.True(t, drawdowns[0].Ongoing())
+		assert.InDelta(t, 0.1, drawdowns[0].Depth, 1e-9)
+	})
+
+	t.Run("no losses", func(t *testing.T) {
+		assert.Empty(t, Drawdowns([]float64{0.1, 0, 0.2}))
+		assert.Empty(t, Drawdowns(nil))
+	})
+
+	t.Run("deepest matches MaxDrawdown", func(t *testing.T) {
+		data, err := loadTestdataReturns(filepath.FromSlash("testdata/metrics.tsv"), 3)
+		require.NoError(t, err)
+		portfolio := data[1]
+		slices.Reverse(portfolio)
+
+		var deepest float64
+		for _, drawdown := range Drawdowns(portfolio) {
+			deepest = max(deepest, drawdown.Depth)
+		}
+		maxDrawdown, _ := MaxDrawdown(portfolio)
+		assert.InDelta(t, maxDrawdown, deepest, 1e-9)
+	})
+}
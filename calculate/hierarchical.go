@@ -0,0 +1,157 @@
+package calculate
+
+import (
+	"math"
+)
+
+// Cluster is a node in the tree built by HierarchicalClusters.
+// Leaf nodes have an Asset index and no children. Parent nodes have an Asset index of -1
+// and Distance is the linkage distance at which the children were merged.
+type Cluster struct {
+	Asset    int      `json:"asset"           bson:"asset"`
+	Distance float64  `json:"distance"        bson:"distance"`
+	Left     *Cluster `json:"left,omitempty"  bson:"left,omitempty"`
+	Right    *Cluster `json:"right,omitempty" bson:"right,omitempty"`
+}
+
+func (cluster *Cluster) IsLeaf() bool { return cluster.Left == nil && cluster.Right == nil }
+
+// Assets returns the asset indexes of the leaves from left to right.
+// This is the quasi-diagonal order used by HierarchicalRiskParityWeights.
+func (cluster *Cluster) Assets() []int {
+	if cluster == nil {
+		return nil
+	}
+	if cluster.IsLeaf() {
+		return []int{cluster.Asset}
+	}
+	return append(cluster.Left.Assets(), cluster.Right.Assets()...)
+}
+
+// CorrelationDistances converts correlations to distances between zero and one using sqrt((1 - correlation) / 2).
+func CorrelationDistances(correlations [][]float64) [][]float64 {
+	distances := make([][]float64, len(correlations))
+	for i, row := range correlations {
+		distances[i] = make([]float64, len(row))
+		for j, c := range row {
+			distances[i][j] = math.Sqrt(math.Max(0, (1-c)/2))
+		}
+	}
+	return distances
+}
+
+// HierarchicalClusters builds a tree with single linkage clustering. Assets are compared using the
+// euclidean distance between their columns in the correlation distance matrix (see CorrelationDistances).
+// Ties are broken by merging the clusters containing the lowest asset indexes so the result is deterministic.
+func HierarchicalClusters(correlations [][]float64) *Cluster {
+	n := len(correlations)
+	if n == 0 {
+		return nil
+	}
+	for _, row := range correlations {
+		if len(row) != n {
+			panic("correlations must be a square matrix")
+		}
+	}
+
+	distances := CorrelationDistances(correlations)
+	linkage := make([][]float64, n)
+	for i := range linkage {
+		linkage[i] = make([]float64, n)
+		for j := range linkage[i] {
+			sum := 0.0
+			for k := 0; k < n; k++ {
+				d := distances[k][i] - distances[k][j]
+				sum += d * d
+			}
+			linkage[i][j] = math.Sqrt(sum)
+		}
+	}
+
+	clusters := make([]*Cluster, n)
+	members := make([][]int, n)
+	for i := range clusters {
+		clusters[i] = &Cluster{Asset: i}
+		members[i] = []int{i}
+	}
+
+	for len(clusters) > 1 {
+		left, right, closest := 0, 1, math.Inf(1)
+		for a := 0; a < len(clusters); a++ {
+			for b := a + 1; b < len(clusters); b++ {
+				if d := singleLinkage(linkage, members[a], members[b]); d < closest {
+					left, right, closest = a, b, d
+				}
+			}
+		}
+		merged := &Cluster{Asset: -1, Distance: closest, Left: clusters[left], Right: clusters[right]}
+		clusters[left] = merged
+		members[left] = append(members[left], members[right]...)
+		clusters = append(clusters[:right], clusters[right+1:]...)
+		members = append(members[:right], members[right+1:]...)
+	}
+
+	return clusters[0]
+}
+
+func singleLinkage(linkage [][]float64, a, b []int) float64 {
+	d := math.Inf(1)
+	for _, i := range a {
+		for _, j := range b {
+			d = math.Min(d, linkage[i][j])
+		}
+	}
+	return d
+}
+
+// HierarchicalRiskParityWeights sets ws using hierarchical risk parity. The assets are clustered (see HierarchicalClusters),
+// ordered by the tree (quasi-diagonalization), and then weight is split between each half of the ordered assets in inverse
+// proportion to the variance of the inverse variance weighted halves (recursive bisection).
+// It returns the cluster tree.
+func HierarchicalRiskParityWeights(ws []float64, vols []float64, correlations [][]float64) *Cluster {
+	checkOptimizerInputs(ws, vols, correlations)
+	tree := HierarchicalClusters(correlations)
+	for i := range ws {
+		ws[i] = 1
+	}
+	recursiveBisection(ws, tree.Assets(), vols, correlations)
+	return tree
+}
+
+func recursiveBisection(ws []float64, assets []int, vols []float64, correlations [][]float64) {
+	if len(assets) < 2 {
+		return
+	}
+	left, right := assets[:len(assets)/2], assets[len(assets)/2:]
+	leftVariance := clusterVariance(left, vols, correlations)
+	rightVariance := clusterVariance(right, vols, correlations)
+	alpha := 0.5 // split evenly when both variances are zero or not defined
+	if total := leftVariance + rightVariance; total > 0 {
+		alpha = 1 - leftVariance/total
+	}
+	for _, i := range left {
+		ws[i] *= alpha
+	}
+	for _, i := range right {
+		ws[i] *= 1 - alpha
+	}
+	recursiveBisection(ws, left, vols, correlations)
+	recursiveBisection(ws, right, vols, correlations)
+}
+
+// clusterVariance returns the variance of the inverse variance weighted portfolio of assets.
+func clusterVariance(assets []int, vols []float64, correlations [][]float64) float64 {
+	subVols := make([]float64, len(assets))
+	for i, a := range assets {
+		subVols[i] = vols[a]
+	}
+	ws := make([]float64, len(assets))
+	InverseVarianceWeights(ws, subVols)
+	variance := 0.0
+	for i, a := range assets {
+		for j, b := range assets {
+			variance += ws[i] * ws[j] * vols[a] * vols[b] * correlations[a][b]
+		}
+	}
+	return variance
+}
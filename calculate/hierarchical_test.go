@@ -0,0 +1,66 @@
+package calculate_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+func TestHierarchicalClusters(t *testing.T) {
+	correlations := [][]float64{
+		{1, 0, 0.9, 0},
+		{0, 1, 0, 0.8},
+		{0.9, 0, 1, 0},
+		{0, 0.8, 0, 1},
+	}
+	tree := calculate.HierarchicalClusters(correlations)
+	require.NotNil(t, tree)
+	assert.Equal(t, []int{0, 2, 1, 3}, tree.Assets())
+	assert.Equal(t, -1, tree.Asset)
+	assert.Equal(t, []int{0, 2}, tree.Left.Assets())
+	assert.Equal(t, []int{1, 3}, tree.Right.Assets())
+	assert.Less(t, tree.Left.Distance, tree.Right.Distance, "more correlated assets are merged first")
+	assert.Less(t, tree.Right.Distance, tree.Distance)
+
+	assert.Nil(t, calculate.HierarchicalClusters(nil))
+}
+
+func TestHierarchicalRiskParityWeights(t *testing.T) {
+	t.Run("clustered", func(t *testing.T) {
+		ws := make([]float64, 4)
+		tree := calculate.HierarchicalRiskParityWeights(ws, []float64{0.1, 0.1, 0.1, 0.1}, [][]float64{
+			{1, 0, 0.9, 0},
+			{0, 1, 0, 0.8},
+			{0.9, 0, 1, 0},
+			{0, 0.8, 0, 1},
+		})
+		assert.Equal(t, []int{0, 2, 1, 3}, tree.Assets())
+		// the cluster of the more correlated pair {0, 2} has more variance so it gets less weight
+		alpha := 1 - 0.0095/(0.0095+0.009)
+		assert.InDeltaSlice(t, []float64{alpha / 2, (1 - alpha) / 2, alpha / 2, (1 - alpha) / 2}, ws, 1e-9)
+	})
+	t.Run("two assets", func(t *testing.T) {
+		ws := make([]float64, 2)
+		calculate.HierarchicalRiskParityWeights(ws, []float64{0.1, 0.2}, [][]float64{{1, 0}, {0, 1}})
+		assert.InDeltaSlice(t, []float64{0.8, 0.2}, ws, 1e-9)
+	})
+	t.Run("zero variance", func(t *testing.T) {
+		ws := make([]float64, 3)
+		calculate.HierarchicalRiskParityWeights(ws, []float64{0, 0, 0}, [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}})
+		for _, w := range ws {
+			assert.False(t, math.IsNaN(w))
+		}
+		assert.InDelta(t, 1, ws[0]+ws[1]+ws[2], 1e-9)
+	})
+	t.Run("deterministic", func(t *testing.T) {
+		correlations := [][]float64{{1, 0.5, 0.5}, {0.5, 1, 0.5}, {0.5, 0.5, 1}}
+		first, second := make([]float64, 3), make([]float64, 3)
+		calculate.HierarchicalRiskParityWeights(first, []float64{0.1, 0.1, 0.1}, correlations)
+		calculate.HierarchicalRiskParityWeights(second, []float64{0.1, 0.1, 0.1}, correlations)
+		assert.Equal(t, first, second)
+	})
+}
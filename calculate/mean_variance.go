@@ -0,0 +1,87 @@
+package calculate
+
+import (
+	"context"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// MinimumVarianceWeights sets ws to the weights with the lowest expected portfolio volatility
+// that satisfy constraints. The zero value WeightConstraints only allows long positions.
+func MinimumVarianceWeights(ctx context.Context, ws []float64, vols []float64, correlations [][]float64, constraints WeightConstraints) error {
+	checkOptimizerInputs(ws, vols, correlations)
+	return optWeights(ctx, ws, constraints, func(ws []float64) float64 {
+		risk, _ := PortfolioVolatility(ws, vols, correlations)
+		return risk
+	})
+}
+
+// MaximumSharpeRatioWeights sets ws to the weights with the highest expected Sharpe ratio that satisfy constraints.
+// The expectedReturns, vols, and riskFreeReturn must be expressed over the same period (for example annualized).
+func MaximumSharpeRatioWeights(ctx context.Context, ws []float64, expectedReturns, vols []float64, correlations [][]float64, riskFreeReturn float64, constraints WeightConstraints) error {
+	checkOptimizerInputs(ws, vols, correlations)
+	if len(ws) != len(expectedReturns) {
+		panic("length of weights and expected returns must be equal")
+	}
+	return optWeights(ctx, ws, constraints, func(ws []float64) float64 {
+		risk, _ := PortfolioVolatility(ws, vols, correlations)
+		if risk == 0 {
+			return 0
+		}
+		return -(floats.Dot(ws, expectedReturns) - riskFreeReturn) / risk
+	})
+}
+
+// MeanVarianceUtilityWeights sets ws to the weights that satisfy constraints and maximize the mean-variance utility
+// expectedReturn - riskAversion/2 * variance. The expectedReturns and vols must be expressed over the same period (for example annualized).
+func MeanVarianceUtilityWeights(ctx context.Context, ws []float64, expectedReturns, vols []float64, correlations [][]float64, riskAversion float64, constraints WeightConstraints) error {
+	checkOptimizerInputs(ws, vols, correlations)
+	if len(ws) != len(expectedReturns) {
+		panic("length of weights and expected returns must be equal")
+	}
+	return optWeights(ctx, ws, constraints, func(ws []float64) float64 {
+		risk, _ := PortfolioVolatility(ws, vols, correlations)
+		return -(floats.Dot(ws, expectedReturns) - riskAversion/2*risk*risk)
+	})
+}
+
+// TargetVolatilityWeights sets ws to the weights with the highest expected return
+// that satisfy constraints where the expected portfolio volatility does not exceed targetVolatility.
+// When no weights meet the target, ws is set to weights with volatility as close to the target as possible.
+// The expectedReturns, vols, and targetVolatility must be expressed over the same period (for example annualized).
+func TargetVolatilityWeights(ctx context.Context, ws []float64, expectedReturns, vols []float64, correlations [][]float64, targetVolatility float64, constraints WeightConstraints) error {
+	checkOptimizerInputs(ws, vols, correlations)
+	if len(ws) != len(expectedReturns) {
+		panic("length of weights and expected returns must be equal")
+	}
+	if targetVolatility <= 0 {
+		panic("target volatility must be greater than zero")
+	}
+	maxAbsReturn := 0.0
+	for _, r := range expectedReturns {
+		maxAbsReturn = math.Max(maxAbsReturn, math.Abs(r))
+	}
+	return optWeights(ctx, ws, constraints, func(ws []float64) float64 {
+		risk, _ := PortfolioVolatility(ws, vols, correlations)
+		if excess := risk - targetVolatility; excess > 0 {
+			// any weights above the target are worse than every weight within the target
+			return maxAbsReturn + excess/targetVolatility
+		}
+		return -floats.Dot(ws, expectedReturns)
+	})
+}
+
+func checkOptimizerInputs(ws []float64, vols []float64, correlations [][]float64) {
+	if len(ws) != len(vols) {
+		panic("length of weights and volatilizes must be equal")
+	}
+	if len(ws) != len(correlations) {
+		panic("length of weights and correlations must be equal")
+	}
+	for _, row := range correlations {
+		if len(row) != len(correlations) {
+			panic("correlations must be a square matrix")
+		}
+	}
+}
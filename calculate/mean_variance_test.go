@@ -0,0 +1,63 @@
+package calculate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+func TestMinimumVarianceWeights(t *testing.T) {
+	ws := []float64{0.5, 0.5}
+	err := calculate.MinimumVarianceWeights(context.Background(), ws, []float64{0.1, 0.2}, [][]float64{{1, 0}, {0, 1}}, calculate.WeightConstraints{})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.8, 0.2}, ws, 0.001)
+}
+
+func TestMaximumSharpeRatioWeights(t *testing.T) {
+	ws := []float64{0.5, 0.5}
+	err := calculate.MaximumSharpeRatioWeights(context.Background(), ws, []float64{0.07, 0.12}, []float64{0.1, 0.2}, [][]float64{{1, 0}, {0, 1}}, 0.02, calculate.WeightConstraints{})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{2.0 / 3, 1.0 / 3}, ws, 0.001)
+}
+
+func TestMeanVarianceUtilityWeights(t *testing.T) {
+	vols, correlations := []float64{0.15, 0.05}, [][]float64{{1, 0.2}, {0.2, 1}}
+	implied := calculate.EquilibriumReturns([]float64{0.6, 0.4}, vols, correlations, calculate.DefaultRiskAversion)
+
+	t.Run("equilibrium returns imply the market weights", func(t *testing.T) {
+		ws := []float64{0.5, 0.5}
+		err := calculate.MeanVarianceUtilityWeights(context.Background(), ws, implied, vols, correlations, calculate.DefaultRiskAversion, calculate.WeightConstraints{})
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.6, 0.4}, ws, 0.001)
+	})
+	t.Run("constrained", func(t *testing.T) {
+		ws := []float64{0.5, 0.5}
+		err := calculate.MeanVarianceUtilityWeights(context.Background(), ws, implied, vols, correlations, calculate.DefaultRiskAversion, calculate.WeightConstraints{
+			Maximum: []float64{0.5, 1},
+		})
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.5, 0.5}, ws, 0.001)
+	})
+}
+
+func TestTargetVolatilityWeights(t *testing.T) {
+	t.Run("target is reachable", func(t *testing.T) {
+		ws := []float64{0.5, 0.5}
+		vols, correlations := []float64{0.05, 0.20}, [][]float64{{1, 0}, {0, 1}}
+		err := calculate.TargetVolatilityWeights(context.Background(), ws, []float64{0.02, 0.10}, vols, correlations, 0.10, calculate.WeightConstraints{})
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.517, 0.483}, ws, 0.001)
+		risk, _ := calculate.PortfolioVolatility(ws, vols, correlations)
+		assert.LessOrEqual(t, risk, 0.10)
+	})
+	t.Run("target is above the riskiest asset", func(t *testing.T) {
+		ws := []float64{0.5, 0.5}
+		err := calculate.TargetVolatilityWeights(context.Background(), ws, []float64{0.02, 0.10}, []float64{0.05, 0.20}, [][]float64{{1, 0}, {0, 1}}, 0.5, calculate.WeightConstraints{})
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0, 1}, ws, 0.001)
+	})
+}
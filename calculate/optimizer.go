@@ -24,7 +24,18 @@ func checkTries(ctx context.Context, try int) error {
 	}
 }
 
-func optWeights(ctx context.Context, weights []float64, fn func(ws []float64) float64) error {
+// groupViolationPenalty is large relative to the objective functions so
+// the optimizer prefers weights satisfying the group constraints.
+const groupViolationPenalty = 1_000
+
+// optWeights minimizes fn over weights that sum to one and satisfy constraints.
+// The search space is mapped onto feasible weights by WeightConstraints.project.
+func optWeights(ctx context.Context, weights []float64, constraints WeightConstraints, fn func(ws []float64) float64) error {
+	if err := constraints.Validate(len(weights)); err != nil {
+		return err
+	}
+	lower, upper := constraints.bounds(len(weights))
+
 	var (
 		try = 0
 		m   = &optimize.NelderMead{}
@@ -39,8 +50,8 @@ func optWeights(ctx context.Context, weights []float64, fn func(ws []float64) fl
 		p  = optimize.Problem{
 			Func: func(x []float64) float64 {
 				copy(ws, x)
-				scaleToUnitRange(ws)
-				return fn(ws)
+				constraints.project(ws, lower, upper)
+				return fn(ws) + groupViolationPenalty*constraints.groupViolation(ws)
 			},
 			Status: func() (optimize.Status, error) {
 				err := checkTries(ctx, try)
@@ -52,26 +63,22 @@ func optWeights(ctx context.Context, weights []float64, fn func(ws []float64) fl
 			},
 		}
 	)
-	optResult, err := optimize.Minimize(p, weights, s, m)
+	initial := make([]float64, len(weights))
+	copy(initial, weights)
+	constraints.project(initial, lower, upper)
+
+	optResult, err := optimize.Minimize(p, initial, s, m)
 	if err != nil {
 		return err
 	}
 
 	copy(weights, optResult.X)
-	scaleToUnitRange(weights)
+	constraints.project(weights, lower, upper)
+	if constraints.groupViolation(weights) > groupConstraintResultTolerance {
+		return errors.New("failed to find weights satisfying the group weight constraints")
+	}
 
 	return nil
 }
 
-func scaleToUnitRange(list []float64) {
-	sum := 0.0
-	for _, v := range list {
-		sum += v
-	}
-	if sum == 0 {
-		return
-	}
-	for i := range list {
-		list[i] /= sum
-	}
-}
+const groupConstraintResultTolerance = 1e-6
@@ -0,0 +1,102 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Regression is the result of an ordinary least squares regression.
+type Regression struct {
+	Intercept    float64   `json:"intercept"    bson:"intercept"`
+	Coefficients []float64 `json:"coefficients" bson:"coefficients"`
+	RSquared     float64   `json:"rSquared"     bson:"rSquared"`
+	// InterceptTStat and TStats are the estimates divided by their standard errors.
+	// They are zero when the residuals are all zero.
+	InterceptTStat float64   `json:"interceptTStat" bson:"interceptTStat"`
+	TStats         []float64 `json:"tStats"         bson:"tStats"`
+	// ResidualStdDev is the standard deviation of the residuals adjusted for the degrees of freedom.
+	ResidualStdDev float64 `json:"residualStdDev" bson:"residualStdDev"`
+	// Residuals are the differences between y and the fitted values.
+	Residuals []float64 `json:"residuals,omitempty" bson:"residuals,omitempty"`
+}
+
+// Predict returns the fitted value for one observation of the regressors.
+func (regression Regression) Predict(xs []float64) float64 {
+	value := regression.Intercept
+	for i, x := range xs {
+		value += regression.Coefficients[i] * x
+	}
+	return value
+}
+
+// OrdinaryLeastSquares regresses y on the columns of xs with an intercept.
+// Each column of xs must have the same length as y.
+func OrdinaryLeastSquares(y []float64, xs [][]float64) (Regression, error) {
+	n, k := len(y), len(xs)
+	for i, x := range xs {
+		if len(x) != n {
+			return Regression{}, fmt.Errorf("regressor %d has %d values but expected %d", i, len(x), n)
+		}
+	}
+	if n <= k+1 {
+		return Regression{}, errors.New("not enough observations for regression")
+	}
+
+	design := mat.NewDense(n, k+1, nil)
+	for i := 0; i < n; i++ {
+		design.Set(i, 0, 1)
+		for j := range xs {
+			design.Set(i, j+1, xs[j][i])
+		}
+	}
+	var qr mat.QR
+	qr.Factorize(design)
+	var beta mat.Dense
+	if err := qr.SolveTo(&beta, false, mat.NewDense(n, 1, y)); err != nil {
+		return Regression{}, fmt.Errorf("regression failed: %w", err)
+	}
+
+	result := Regression{
+		Intercept:    beta.At(0, 0),
+		Coefficients: make([]float64, k),
+		Residuals:    make([]float64, n),
+	}
+	for j := range result.Coefficients {
+		result.Coefficients[j] = beta.At(j+1, 0)
+	}
+	row := make([]float64, k)
+	for i := range y {
+		for j := range xs {
+			row[j] = xs[j][i]
+		}
+		result.Residuals[i] = y[i] - result.Predict(row)
+	}
+	if variance := stat.Variance(y, nil); variance > 0 {
+		result.RSquared = 1 - stat.Variance(result.Residuals, nil)/variance
+	}
+
+	var sumOfSquares float64
+	for _, e := range result.Residuals {
+		sumOfSquares += e * e
+	}
+	residualVariance := sumOfSquares / float64(n-k-1)
+	result.ResidualStdDev = math.Sqrt(residualVariance)
+	result.TStats = make([]float64, k)
+	if residualVariance == 0 {
+		return result, nil
+	}
+	var gram, inverse mat.Dense
+	gram.Mul(design.T(), design)
+	if err := inverse.Inverse(&gram); err != nil {
+		return Regression{}, fmt.Errorf("regression failed: %w", err)
+	}
+	result.InterceptTStat = result.Intercept / math.Sqrt(residualVariance*inverse.At(0, 0))
+	for j := range result.TStats {
+		result.TStats[j] = result.Coefficients[j] / math.Sqrt(residualVariance*inverse.At(j+1, j+1))
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package calculate_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/stat"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+func TestOrdinaryLeastSquares(t *testing.T) {
+	x1 := []float64{0.01, -0.02, 0.03, 0.00, 0.015, -0.01}
+	x2 := []float64{0.00, 0.01, -0.01, 0.02, 0.005, 0.01}
+	y := make([]float64, len(x1))
+	for i := range y {
+		y[i] = 0.001 + 1.5*x1[i] - 0.5*x2[i]
+	}
+
+	regression, err := calculate.OrdinaryLeastSquares(y, [][]float64{x1, x2})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.001, regression.Intercept, 1e-12)
+	assert.InDeltaSlice(t, []float64{1.5, -0.5}, regression.Coefficients, 1e-12)
+	assert.InDelta(t, 1, regression.RSquared, 1e-12)
+	assert.InDelta(t, y[2], regression.Predict([]float64{x1[2], x2[2]}), 1e-12)
+
+	_, err = calculate.OrdinaryLeastSquares(y[:2], [][]float64{x1[:2], x2[:2]})
+	assert.Error(t, err)
+	_, err = calculate.OrdinaryLeastSquares(y, [][]float64{x1[:2]})
+	assert.Error(t, err)
+
+	t.Run("t statistics", func(t *testing.T) {
+		x := []float64{0.01, -0.02, 0.03, 0.00, 0.015, -0.01, 0.02, -0.005}
+		noise := []float64{0.002, -0.001, -0.003, 0.001, 0.002, -0.002, 0.0005, 0.0005}
+		y := make([]float64, len(x))
+		for i := range y {
+			y[i] = 0.8*x[i] + noise[i]
+		}
+		regression, err := calculate.OrdinaryLeastSquares(y, [][]float64{x})
+		require.NoError(t, err)
+
+		var sumOfSquares float64
+		for _, e := range regression.Residuals {
+			sumOfSquares += e * e
+		}
+		residualVariance := sumOfSquares / float64(len(y)-2)
+		assert.InDelta(t, math.Sqrt(residualVariance), regression.ResidualStdDev, 1e-12)
+
+		mean := stat.Mean(x, nil)
+		var sxx, sumOfX2 float64
+		for _, v := range x {
+			sxx += (v - mean) * (v - mean)
+			sumOfX2 += v * v
+		}
+		slopeStandardError := math.Sqrt(residualVariance / sxx)
+		interceptStandardError := math.Sqrt(residualVariance * sumOfX2 / (float64(len(x)) * sxx))
+		assert.InDelta(t, regression.Coefficients[0]/slopeStandardError, regression.TStats[0], 1e-9)
+		assert.InDelta(t, regression.Intercept/interceptStandardError, regression.InterceptTStat, 1e-9)
+	})
+}
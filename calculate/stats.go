@@ -67,6 +67,47 @@ func BetaToBenchmark(portfolio, benchmark []float64) float64 {
 	return slope
 }
 
+// UpCaptureRatio is the geometric mean portfolio return divided by the geometric mean benchmark return
+// over the periods when the benchmark return is positive.
+func UpCaptureRatio(portfolio, benchmark []float64) float64 {
+	return captureRatio(portfolio, benchmark, func(b float64) bool { return b > 0 })
+}
+
+// DownCaptureRatio is the geometric mean portfolio return divided by the geometric mean benchmark return
+// over the periods when the benchmark return is negative.
+func DownCaptureRatio(portfolio, benchmark []float64) float64 {
+	return captureRatio(portfolio, benchmark, func(b float64) bool { return b < 0 })
+}
+
+// BattingAverage is the fraction of periods when the portfolio return is greater than the benchmark return.
+func BattingAverage(portfolio, benchmark []float64) float64 {
+	if len(portfolio) == 0 {
+		return 0
+	}
+	count := 0
+	for i := range portfolio {
+		if portfolio[i] > benchmark[i] {
+			count++
+		}
+	}
+	return float64(count) / float64(len(portfolio))
+}
+
+func captureRatio(portfolio, benchmark []float64, include func(float64) bool) float64 {
+	var p, b []float64
+	for i := range benchmark {
+		if include(benchmark[i]) {
+			p = append(p, portfolio[i])
+			b = append(b, benchmark[i])
+		}
+	}
+	if len(b) == 0 {
+		return 0
+	}
+	n := float64(len(b))
+	return (math.Pow(productOfReturnValues(p), 1/n) - 1) / (math.Pow(productOfReturnValues(b), 1/n) - 1)
+}
+
 func ValueAtRisk(values []float64, portfolioValue, confidenceLevel, periodsPerYear float64) float64 {
 	normal := distuv.Normal{
 		Mu:    0,
@@ -5,6 +5,7 @@ import (
 	"encoding/csv"
 	"errors"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"slices"
@@ -221,3 +222,17 @@ func TestEffectiveNumberOfBets(t *testing.T) {
 		})
 	}
 }
+
+func TestCaptureRatios(t *testing.T) {
+	portfolio := []float64{0.02, -0.01, 0.03, -0.02}
+	benchmark := []float64{0.01, -0.02, 0.02, 0.01}
+
+	up := (math.Cbrt(1.02*1.03*0.98) - 1) / (math.Cbrt(1.01*1.02*1.01) - 1)
+	assert.InDelta(t, up, UpCaptureRatio(portfolio, benchmark), 1e-12)
+	assert.InDelta(t, 0.5, DownCaptureRatio(portfolio, benchmark), 1e-12)
+	assert.InDelta(t, 0.75, BattingAverage(portfolio, benchmark), 1e-12)
+
+	assert.InDelta(t, 1, UpCaptureRatio(benchmark, benchmark), 1e-12)
+	assert.Zero(t, DownCaptureRatio([]float64{0.01}, []float64{0.01}), "no down periods")
+	assert.Zero(t, BattingAverage(nil, nil))
+}
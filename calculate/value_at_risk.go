@@ -0,0 +1,140 @@
+package calculate
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distmv"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// The value at risk functions in this file return the one period return at the lower tail of the distribution,
+// so losses are negative numbers. Unlike ValueAtRisk they do not annualize or scale by a portfolio value.
+
+// HistoricalValueAtRisk returns the (1 - confidenceLevel) empirical quantile of values.
+// That is the k-th lowest value where k is (1 - confidenceLevel) * len(values) rounded up.
+func HistoricalValueAtRisk(values []float64, confidenceLevel float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	// round before taking the ceiling so 1 - 0.95 selects the 5th of 100 values rather than the 6th
+	count := math.Ceil(math.Round((1-confidenceLevel)*float64(len(sorted))*1e9) / 1e9)
+	return sorted[min(max(int(count), 1), len(sorted))-1]
+}
+
+// HistoricalConditionalValueAtRisk (expected shortfall) returns the mean of the values at or below HistoricalValueAtRisk.
+func HistoricalConditionalValueAtRisk(values []float64, confidenceLevel float64) float64 {
+	valueAtRisk := HistoricalValueAtRisk(values, confidenceLevel)
+	var sum float64
+	var count int
+	for _, v := range values {
+		if v <= valueAtRisk {
+			sum += v
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// ParametricValueAtRisk returns the value at risk of a zero mean normal distribution with the sample standard deviation of values.
+// It uses the same estimator as ValueAtRiskContributions so the component values sum to it.
+func ParametricValueAtRisk(values []float64, confidenceLevel float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	return distuv.UnitNormal.Quantile(1-confidenceLevel) * stat.StdDev(values, nil)
+}
+
+// ParametricConditionalValueAtRisk returns the expected shortfall of a zero mean normal distribution with the
+// sample standard deviation of values. It uses the same distribution as ParametricValueAtRisk so it is never above it.
+func ParametricConditionalValueAtRisk(values []float64, confidenceLevel float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	z := distuv.UnitNormal.Quantile(1 - confidenceLevel)
+	return -stat.StdDev(values, nil) * distuv.UnitNormal.Prob(z) / (1 - confidenceLevel)
+}
+
+// CornishFisherValueAtRisk (modified value at risk) adjusts the normal quantile for the skew and excess kurtosis of values.
+func CornishFisherValueAtRisk(values []float64, confidenceLevel float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean, stdDev := stat.MeanStdDev(values, nil)
+	skew, kurtosis := stat.Skew(values, nil), stat.ExKurtosis(values, nil)
+	z := distuv.UnitNormal.Quantile(1 - confidenceLevel)
+	z = z +
+		(z*z-1)*skew/6 +
+		(z*z*z-3*z)*kurtosis/24 -
+		(2*z*z*z-5*z)*skew*skew/36
+	return mean + z*stdDev
+}
+
+// ValueAtRiskContributions returns the parametric (zero mean, normal) marginal and component value at risk of each asset.
+// The assets are the columns of assetReturns. The component values sum to the ParametricValueAtRisk of the
+// weighted returns; both use the sample standard deviation.
+// The marginal value is the change in portfolio value at risk for a small change in the asset weight.
+func ValueAtRiskContributions(weights []float64, assetReturns [][]float64, confidenceLevel float64) (marginal, component []float64) {
+	stdDevs := make([]float64, len(assetReturns))
+	for i := range assetReturns {
+		stdDevs[i] = RiskFromStdDev(assetReturns[i])
+	}
+	_, riskContributions := PortfolioVolatility(weights, stdDevs, CorrelationMatrix(assetReturns))
+	z := distuv.UnitNormal.Quantile(1 - confidenceLevel)
+	marginal = make([]float64, len(weights))
+	component = make([]float64, len(weights))
+	for i := range weights {
+		component[i] = finiteOrZero(z * riskContributions[i])
+		if weights[i] != 0 {
+			marginal[i] = component[i] / weights[i]
+		}
+	}
+	return marginal, component
+}
+
+// MonteCarloValueAtRisk simulates portfolio returns from a multivariate normal distribution with the sample means
+// and covariances of the columns of assetReturns. It returns the historical value at risk and conditional value at risk
+// of the simulated returns.
+func MonteCarloValueAtRisk(weights []float64, assetReturns [][]float64, confidenceLevel float64, simulations int, src rand.Source) (valueAtRisk, conditionalValueAtRisk float64, _ error) {
+	if len(assetReturns) == 0 || len(assetReturns[0]) < 2 {
+		return 0, 0, errors.New("not enough returns to simulate")
+	}
+	n := len(assetReturns)
+	means := make([]float64, n)
+	data := mat.NewDense(len(assetReturns[0]), n, nil)
+	for j := range assetReturns {
+		means[j] = stat.Mean(assetReturns[j], nil)
+		data.SetCol(j, assetReturns[j])
+	}
+	var covariance mat.SymDense
+	stat.CovarianceMatrix(&covariance, data, nil)
+	normal, ok := distmv.NewNormal(means, &covariance, src)
+	if !ok {
+		return 0, 0, errors.New("covariance matrix is not positive definite")
+	}
+	simulated := make([]float64, simulations)
+	draw := make([]float64, n)
+	for s := range simulated {
+		normal.Rand(draw)
+		for j, w := range weights {
+			simulated[s] += w * draw[j]
+		}
+	}
+	return HistoricalValueAtRisk(simulated, confidenceLevel), HistoricalConditionalValueAtRisk(simulated, confidenceLevel), nil
+}
+
+func finiteOrZero(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+package calculate_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+func TestHistoricalValueAtRisk(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[(i*37)%100] = float64(i)/1000 - 0.05
+	}
+	assert.InDelta(t, -0.046, calculate.HistoricalValueAtRisk(values, 0.95), 1e-12)
+	assert.InDelta(t, -0.048, calculate.HistoricalConditionalValueAtRisk(values, 0.95), 1e-12)
+	assert.Zero(t, calculate.HistoricalValueAtRisk(nil, 0.95))
+}
+
+func TestParametricConditionalValueAtRisk(t *testing.T) {
+	values := symmetricReturns(0.02, 50)
+	stdDev := stat.StdDev(values, nil)
+	assert.InDelta(t, -2.0627*stdDev, calculate.ParametricConditionalValueAtRisk(values, 0.95), 1e-5)
+
+	t.Run("positive drift", func(t *testing.T) {
+		drift := make([]float64, len(values))
+		for i := range values {
+			drift[i] = values[i] + 0.05
+		}
+		for _, confidenceLevel := range []float64{0.9, 0.95, 0.99} {
+			assert.LessOrEqual(t, calculate.ParametricConditionalValueAtRisk(drift, confidenceLevel), calculate.ParametricValueAtRisk(drift, confidenceLevel))
+		}
+	})
+}
+
+func TestCornishFisherValueAtRisk(t *testing.T) {
+	t.Run("no skew with thin tails", func(t *testing.T) {
+		values := symmetricReturns(0.02, 50)
+		z := -1.6448536269514722
+		kurtosis := stat.ExKurtosis(values, nil)
+		require.InDelta(t, -2, kurtosis, 0.1)
+		expected := (z + (z*z*z-3*z)*kurtosis/24) * stat.StdDev(values, nil)
+		assert.InDelta(t, expected, calculate.CornishFisherValueAtRisk(values, 0.95), 1e-5)
+	})
+
+	t.Run("negative skew increases the loss", func(t *testing.T) {
+		values := append(symmetricReturns(0.01, 50), -0.08, -0.06)
+		normal := stat.Mean(values, nil) - 1.6449*stat.StdDev(values, nil)
+		assert.Less(t, calculate.CornishFisherValueAtRisk(values, 0.99), normal-0.001)
+	})
+}
+
+func TestValueAtRiskContributions(t *testing.T) {
+	assets := [][]float64{
+		{0.01, -0.02, 0.015, -0.005, 0.02, -0.01},
+		{0.005, -0.01, 0.02, 0.01, -0.015, 0.0},
+		{-0.01, 0.02, 0.0, 0.005, 0.01, -0.02},
+	}
+	weights := []float64{0.5, 0.3, 0.2}
+
+	marginal, component := calculate.ValueAtRiskContributions(weights, assets, 0.95)
+	require.Len(t, component, 3)
+
+	portfolio := make([]float64, len(assets[0]))
+	for j := range assets {
+		floats.AddScaled(portfolio, weights[j], assets[j])
+	}
+	assert.InDelta(t, -1.6449*stat.StdDev(portfolio, nil), floats.Sum(component), 1e-5)
+	assert.InDelta(t, calculate.ParametricValueAtRisk(portfolio, 0.95), floats.Sum(component), 1e-12)
+	for i := range weights {
+		assert.InDelta(t, component[i]/weights[i], marginal[i], 1e-12)
+	}
+}
+
+func TestMonteCarloValueAtRisk(t *testing.T) {
+	src := rand.NewPCG(1, 2)
+	assets := [][]float64{make([]float64, 500), make([]float64, 500)}
+	rnd := rand.New(rand.NewPCG(3, 4))
+	for i := range assets[0] {
+		assets[0][i] = 0.01 * rnd.NormFloat64()
+		assets[1][i] = 0.5*assets[0][i] + 0.005*rnd.NormFloat64()
+	}
+	weights := []float64{0.6, 0.4}
+
+	valueAtRisk, conditionalValueAtRisk, err := calculate.MonteCarloValueAtRisk(weights, assets, 0.95, 100_000, src)
+	require.NoError(t, err)
+
+	portfolio := make([]float64, len(assets[0]))
+	for j := range assets {
+		floats.AddScaled(portfolio, weights[j], assets[j])
+	}
+	mean, stdDev := stat.MeanStdDev(portfolio, nil)
+	assert.InDelta(t, mean-1.6449*stdDev, valueAtRisk, 0.02*stdDev)
+	assert.InDelta(t, mean+calculate.ParametricConditionalValueAtRisk(portfolio, 0.95), conditionalValueAtRisk, 0.03*stdDev)
+
+	_, _, err = calculate.MonteCarloValueAtRisk(weights, nil, 0.95, 10, src)
+	assert.Error(t, err)
+}
+
+func symmetricReturns(value float64, pairs int) []float64 {
+	values := make([]float64, 0, 2*pairs)
+	for range pairs {
+		values = append(values, value, -value)
+	}
+	return values
+}
@@ -31,7 +31,15 @@ func InverseVarianceWeights(ws []float64, vols []float64) {
 	}
 }
 
+// EqualRiskContributionWeights sets ws to long-only weights where each asset contributes the same amount of risk.
+// See EqualRiskContributionWeightsWithConstraints.
 func EqualRiskContributionWeights(ctx context.Context, ws []float64, vols []float64, correlations [][]float64) error {
+	return EqualRiskContributionWeightsWithConstraints(ctx, ws, vols, correlations, WeightConstraints{})
+}
+
+// EqualRiskContributionWeightsWithConstraints sets ws to weights where each asset contributes the same amount of risk.
+// The weights must satisfy constraints; the zero value WeightConstraints only allows long positions.
+func EqualRiskContributionWeightsWithConstraints(ctx context.Context, ws []float64, vols []float64, correlations [][]float64, constraints WeightConstraints) error {
 	if len(ws) != len(vols) {
 		panic("length of weights and volatilizes must be equal")
 	}
@@ -45,7 +53,7 @@ func EqualRiskContributionWeights(ctx context.Context, ws []float64, vols []floa
 	}
 
 	target := 1.0 / float64(len(vols))
-	return optWeights(ctx, ws, func(ws []float64) float64 {
+	return optWeights(ctx, ws, constraints, func(ws []float64) float64 {
 		riskWeights := RiskWeights(PortfolioVolatility(vols, ws, correlations))
 		var diff float64
 		for i := range riskWeights {
@@ -34,6 +34,18 @@ type Component struct {
 	Type  string `yaml:"type,omitempty"  json:"type,omitempty"  bson:"type"`
 	ID    string `yaml:"id,omitempty"    json:"id,omitempty"    bson:"id"`
 	Label string `yaml:"label,omitempty" json:"label,omitempty" bson:"label"`
+
+	// Proxy is used to backfill returns before the component's first return. See BackfillComponentReturns.
+	Proxy *Proxy `yaml:"proxy,omitempty" json:"proxy,omitempty" bson:"proxy,omitempty"`
+}
+
+// Proxy declares components with a longer history than the component it is set on.
+// With one component, its returns are spliced before the first return (see returns.List.Backfill).
+// With several components, the returns are regressed on theirs and the fitted returns are spliced
+// (see returns.List.BackfillWithRegression).
+type Proxy struct {
+	Components      []Component `yaml:"components"                 json:"components"                bson:"components"`
+	ScaleVolatility bool        `yaml:"scale_volatility,omitempty" json:"scaleVolatility,omitempty" bson:"scale_volatility,omitempty"`
 }
 
 var componentExpression = regexp.MustCompile(`^[a-zA-Z0-9.:]{1,24}$`)
@@ -51,6 +63,16 @@ func (component *Component) Validate() error {
 	if component.Type != "" && !slices.Contains(ComponentTypes(), component.Type) {
 		return fmt.Errorf("component type %q is not a known component type", component.Type)
 	}
+	if component.Proxy != nil {
+		if len(component.Proxy.Components) == 0 {
+			return fmt.Errorf("component %q proxy must list at least one component", component.ID)
+		}
+		for _, proxy := range component.Proxy.Components {
+			if err := proxy.Validate(); err != nil {
+				return fmt.Errorf("component %q proxy: %w", component.ID, err)
+			}
+		}
+	}
 	return nil
 }
 
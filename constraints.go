@@ -0,0 +1,69 @@
+package portfolio
+
+import (
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/portfoliotree/portfolio/allocation"
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+// WeightGroup bounds the sum of the policy weights of the assets with the listed IDs.
+// When Maximum is not set in YAML it defaults to one.
+type WeightGroup struct {
+	Name    string   `yaml:"name,omitempty" json:"name,omitempty" bson:"name,omitempty"`
+	Assets  []string `yaml:"assets"         json:"assets"         bson:"assets"`
+	Minimum float64  `yaml:"minimum"        json:"minimum"        bson:"minimum"`
+	Maximum float64  `yaml:"maximum"        json:"maximum"        bson:"maximum"`
+}
+
+func (group *WeightGroup) UnmarshalYAML(value *yaml.Node) error {
+	type G WeightGroup
+	g := G{Maximum: 1}
+	if err := value.Decode(&g); err != nil {
+		return err
+	}
+	*group = WeightGroup(g)
+	return nil
+}
+
+// WeightConstraints converts the policy weight bounds and groups into constraints for the optimizing algorithms.
+// It returns an error when the bounds do not match the assets or when the constraints are infeasible.
+func (pf *Specification) WeightConstraints() (calculate.WeightConstraints, error) {
+	constraints := calculate.WeightConstraints{
+		Minimum: slices.Clone(pf.Policy.MinimumWeights),
+		Maximum: slices.Clone(pf.Policy.MaximumWeights),
+	}
+	for _, group := range pf.Policy.WeightGroups {
+		gc := calculate.GroupConstraint{
+			Minimum: group.Minimum,
+			Maximum: group.Maximum,
+		}
+		for _, id := range group.Assets {
+			index := slices.IndexFunc(pf.Assets, func(c Component) bool { return c.ID == id })
+			if index < 0 {
+				return calculate.WeightConstraints{}, fmt.Errorf("weight group %q asset %q is not in the portfolio", group.Name, id)
+			}
+			gc.Assets = append(gc.Assets, index)
+		}
+		constraints.Groups = append(constraints.Groups, gc)
+	}
+	if err := constraints.Validate(len(pf.Assets)); err != nil {
+		return calculate.WeightConstraints{}, err
+	}
+	return constraints, nil
+}
+
+// errUnsupportedConstraints returns an error when the policy has weight constraints
+// and alg does not implement allocation.ConstraintSetter so it would ignore them.
+func (pf *Specification) errUnsupportedConstraints(alg allocation.Algorithm) error {
+	if pf.Policy.MinimumWeights == nil && pf.Policy.MaximumWeights == nil && len(pf.Policy.WeightGroups) == 0 {
+		return nil
+	}
+	if _, ok := alg.(allocation.ConstraintSetter); ok {
+		return nil
+	}
+	return fmt.Errorf("weights algorithm %q does not support weight constraints", alg.Name())
+}
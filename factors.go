@@ -0,0 +1,128 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// FactorAnalysis has regressions of the portfolio and asset returns on the factors listed in Metadata.Factors.
+type FactorAnalysis struct {
+	Factors   []Component        `json:"factors"   bson:"factors"`
+	Portfolio FactorRegression   `json:"portfolio" bson:"portfolio"`
+	Assets    []FactorRegression `json:"assets"    bson:"assets"`
+}
+
+// FactorRegression is an ordinary least squares regression of returns on factor returns.
+// Returns and volatilities are annualized using the frequency of the returns.
+// Betas, TStats, and the Factors of the attributions are in the same order as FactorAnalysis.Factors.
+type FactorRegression struct {
+	// Component is the regressed asset. It is the zero value for the portfolio.
+	Component Component `json:"component" bson:"component"`
+	Start     time.Time `json:"start"     bson:"start"`
+	End       time.Time `json:"end"       bson:"end"`
+
+	Alpha              float64   `json:"alpha"              bson:"alpha"`
+	AlphaTStat         float64   `json:"alphaTStat"         bson:"alphaTStat"`
+	Betas              []float64 `json:"betas"              bson:"betas"`
+	TStats             []float64 `json:"tStats"             bson:"tStats"`
+	RSquared           float64   `json:"rSquared"           bson:"rSquared"`
+	ResidualVolatility float64   `json:"residualVolatility" bson:"residualVolatility"`
+
+	// Return splits the annualized arithmetic return into beta times the mean factor return and alpha.
+	Return FactorAttribution `json:"return" bson:"return"`
+	// Risk splits the annualized volatility into factor contributions (beta times the covariance of the
+	// factor and the returns divided by the volatility) and the idiosyncratic (residual) contribution.
+	Risk FactorAttribution `json:"risk" bson:"risk"`
+}
+
+// FactorAttribution splits Total into factor and idiosyncratic parts. Factors and Idiosyncratic sum to Total.
+type FactorAttribution struct {
+	Total         float64   `json:"total"         bson:"total"`
+	Factors       []float64 `json:"factors"       bson:"factors"`
+	Idiosyncratic float64   `json:"idiosyncratic" bson:"idiosyncratic"`
+}
+
+// FactorAnalysis fetches the factors listed in the document metadata and regresses the backtest returns
+// and the returns of each asset on them. Only times where the regressed returns and every factor have
+// a return are used.
+func (d Document) FactorAnalysis(ctx context.Context, provider ComponentReturnsProvider, result backtest.Result) (FactorAnalysis, error) {
+	if len(d.Metadata.Factors) == 0 {
+		return FactorAnalysis{}, errors.New("metadata does not list any factors")
+	}
+	factors, err := provider.ComponentReturnsTable(ctx, d.Metadata.Factors...)
+	if err != nil {
+		return FactorAnalysis{}, err
+	}
+	assets, err := provider.ComponentReturnsTable(ctx, d.Spec.Assets...)
+	if err != nil {
+		return FactorAnalysis{}, err
+	}
+
+	analysis := FactorAnalysis{
+		Factors: d.Metadata.Factors,
+		Assets:  make([]FactorRegression, len(d.Spec.Assets)),
+	}
+	analysis.Portfolio, err = newFactorRegression(result.Returns(), factors)
+	if err != nil {
+		return FactorAnalysis{}, fmt.Errorf("portfolio factor regression failed: %w", err)
+	}
+	for i, asset := range d.Spec.Assets {
+		analysis.Assets[i], err = newFactorRegression(assets.List(i), factors)
+		if err != nil {
+			return FactorAnalysis{}, fmt.Errorf("factor regression for asset %q failed: %w", asset.ID, err)
+		}
+		analysis.Assets[i].Component = asset
+	}
+	return analysis, nil
+}
+
+func newFactorRegression(list returns.List, factors returns.Table) (FactorRegression, error) {
+	table := returns.NewTable(append([]returns.List{list}, factors.Lists()...))
+	values := table.ColumnValues()
+	y, xs := values[0], values[1:]
+	regression, err := calculate.OrdinaryLeastSquares(y, xs)
+	if err != nil {
+		return FactorRegression{}, err
+	}
+	periodsPerYear := table.Frequency().PeriodsPerYear()
+	volatility := calculate.RiskFromStdDev(y)
+
+	result := FactorRegression{
+		Start:              table.FirstTime(),
+		End:                table.LastTime(),
+		Alpha:              regression.Intercept * periodsPerYear,
+		AlphaTStat:         regression.InterceptTStat,
+		Betas:              regression.Coefficients,
+		TStats:             regression.TStats,
+		RSquared:           regression.RSquared,
+		ResidualVolatility: calculate.AnnualizeRisk(regression.ResidualStdDev, periodsPerYear),
+		Return: FactorAttribution{
+			Total:         calculate.AnnualizedArithmeticReturn(y, periodsPerYear),
+			Factors:       make([]float64, len(xs)),
+			Idiosyncratic: regression.Intercept * periodsPerYear,
+		},
+		Risk: FactorAttribution{
+			Total:   calculate.AnnualizeRisk(volatility, periodsPerYear),
+			Factors: make([]float64, len(xs)),
+		},
+	}
+	if volatility > 0 {
+		result.Risk.Idiosyncratic = calculate.AnnualizeRisk(stat.Variance(regression.Residuals, nil)/volatility, periodsPerYear)
+	}
+	for k, x := range xs {
+		beta := regression.Coefficients[k]
+		result.Return.Factors[k] = beta * stat.Mean(x, nil) * periodsPerYear
+		if volatility > 0 {
+			result.Risk.Factors[k] = calculate.AnnualizeRisk(beta*stat.Covariance(x, y, nil)/volatility, periodsPerYear)
+		}
+	}
+	return result, nil
+}
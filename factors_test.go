@@ -0,0 +1,70 @@
+package portfolio_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+)
+
+func TestDocument_FactorAnalysis(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+metadata:
+  factors: [SPY, AGG]
+spec:
+  assets: [AAPL, NFLX]
+  policy:
+    weights: [50, 50]
+    weights_algorithm: Constant Weights
+    rebalancing_interval: Quarterly
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	provider := portfoliotest.ComponentReturnsProvider()
+	assets, err := provider.ComponentReturnsTable(ctx, pf.Spec.Assets...)
+	require.NoError(t, err)
+	result, err := pf.Spec.Backtest(ctx, assets, nil)
+	require.NoError(t, err)
+
+	analysis, err := pf.FactorAnalysis(ctx, provider, result)
+	require.NoError(t, err)
+	assert.Equal(t, pf.Metadata.Factors, analysis.Factors)
+
+	regression := analysis.Portfolio
+	assert.Zero(t, regression.Component)
+	assert.Equal(t, result.ReturnsTable.LastTime(), regression.End)
+	require.Len(t, regression.Betas, 2)
+	require.Len(t, regression.TStats, 2)
+	assert.Greater(t, regression.Betas[0], 0.8, "the portfolio is an equity portfolio")
+	assert.Greater(t, regression.TStats[0], 10.0)
+	assert.Greater(t, regression.RSquared, 0.2)
+	assert.Less(t, regression.RSquared, 1.0)
+	assert.Greater(t, regression.ResidualVolatility, 0.0)
+
+	for _, attribution := range []portfolio.FactorAttribution{regression.Return, regression.Risk} {
+		assert.InDelta(t, attribution.Total, floats.Sum(attribution.Factors)+attribution.Idiosyncratic, 1e-9)
+	}
+	assert.Equal(t, regression.Alpha, regression.Return.Idiosyncratic)
+	assert.InDelta(t, result.Returns().Between(regression.End, regression.Start).AnnualizedRisk(), regression.Risk.Total, 1e-9)
+
+	require.Len(t, analysis.Assets, 2)
+	assert.Equal(t, "AAPL", analysis.Assets[0].Component.ID)
+	assert.Equal(t, "NFLX", analysis.Assets[1].Component.ID)
+	assert.Equal(t, date("2003-09-29"), analysis.Assets[1].Start, "the regression starts with the AGG factor")
+
+	t.Run("no factors", func(t *testing.T) {
+		doc := pf
+		doc.Metadata.Factors = nil
+		_, err := doc.FactorAnalysis(ctx, provider, result)
+		assert.ErrorContains(t, err, "metadata does not list any factors")
+	})
+}
@@ -0,0 +1,137 @@
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"sync"
+
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// FileReturnsProvider implements ComponentReturnsProvider by reading a directory with one file per Component.ID.
+// A JSON file (ID.json) must contain a returns.List (the same layout as portfoliotest/data/returns).
+// A CSV file (ID.csv) is read with returns.ReadCSVColumn; DateColumn and ValueColumn name the columns to read.
+// Other columns are ignored.
+// When both files exist the JSON file is used. Parsed files are cached.
+type FileReturnsProvider struct {
+	Dir fs.FS
+
+	// DateColumn is the CSV header of the date column. It defaults to the first column.
+	DateColumn string
+	// ValueColumn is the CSV header of the value column. It defaults to the first column that is not DateColumn.
+	ValueColumn string
+
+	// Prices should be set when the files contain prices (or quotes) instead of returns.
+	// Returns are then calculated with calculate.HoldingPeriodReturns.
+	Prices bool
+
+	mut   sync.Mutex
+	cache map[string]returns.List
+}
+
+// NewFileReturnsProvider returns a FileReturnsProvider for dir (for example os.DirFS("data/returns")).
+func NewFileReturnsProvider(dir fs.FS) *FileReturnsProvider {
+	return &FileReturnsProvider{Dir: dir}
+}
+
+// ComponentReturnsList implements ComponentReturnsProvider.
+func (provider *FileReturnsProvider) ComponentReturnsList(_ context.Context, component Component) (returns.List, error) {
+	provider.mut.Lock()
+	list, ok := provider.cache[component.ID]
+	provider.mut.Unlock()
+	if ok {
+		return slices.Clone(list), nil
+	}
+
+	list, err := provider.readComponentReturns(component.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.mut.Lock()
+	defer provider.mut.Unlock()
+	if provider.cache == nil {
+		provider.cache = make(map[string]returns.List)
+	}
+	provider.cache[component.ID] = list
+	return slices.Clone(list), nil
+}
+
+// ComponentReturnsTable implements ComponentReturnsProvider.
+func (provider *FileReturnsProvider) ComponentReturnsTable(ctx context.Context, components ...Component) (returns.Table, error) {
+	var table returns.Table
+	for _, component := range components {
+		list, err := provider.ComponentReturnsList(ctx, component)
+		if err != nil {
+			return returns.Table{}, err
+		}
+		table = table.AddColumn(list)
+	}
+	return table, nil
+}
+
+func (provider *FileReturnsProvider) readComponentReturns(id string) (returns.List, error) {
+	if id == "" {
+		return nil, errors.New("component ID must be set")
+	}
+	var (
+		list     returns.List
+		err      error
+		fileName string
+	)
+	for _, ext := range []string{".json", ".csv"} {
+		fileName = id + ext
+		var data []byte
+		data, err = fs.ReadFile(provider.Dir, fileName)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		if ext == ".json" {
+			list, err = readJSONReturns(bytes.NewReader(data))
+		} else {
+			list, err = provider.readCSVReturns(bytes.NewReader(data))
+		}
+		break
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("no returns file for component %q: %w", id, err)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	list.Sort()
+	if provider.Prices {
+		list = pricesToReturns(list)
+	}
+	return list, nil
+}
+
+func readJSONReturns(r io.Reader) (returns.List, error) {
+	var list returns.List
+	return list, json.NewDecoder(r).Decode(&list)
+}
+
+func (provider *FileReturnsProvider) readCSVReturns(r io.Reader) (returns.List, error) {
+	return returns.ReadCSVColumn(r, provider.DateColumn, provider.ValueColumn)
+}
+
+// pricesToReturns converts a newest-first list of prices to returns.
+// The oldest price does not have a return.
+func pricesToReturns(prices returns.List) returns.List {
+	values := calculate.HoldingPeriodReturns(prices.Values())
+	list := make(returns.List, len(values))
+	for i, value := range values {
+		list[i] = returns.New(prices[i].Time, value)
+	}
+	return list
+}
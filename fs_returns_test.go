@@ -0,0 +1,102 @@
+package portfolio_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+)
+
+func TestFileReturnsProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("json", func(t *testing.T) {
+		provider := portfolio.NewFileReturnsProvider(os.DirFS(filepath.FromSlash("portfoliotest/data/returns")))
+		table, err := provider.ComponentReturnsTable(ctx, portfolio.Component{ID: "ACWI"}, portfolio.Component{ID: "AGG"})
+		require.NoError(t, err)
+		expected, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, portfolio.Component{ID: "ACWI"}, portfolio.Component{ID: "AGG"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, table)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		provider := portfolio.NewFileReturnsProvider(fstest.MapFS{
+			"A.csv": {Data: []byte("Date,A\n2021-01-01,0.01\n2021-01-04,\n2021-01-05,-0.02\n")},
+		})
+		list, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "A"})
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, date("2021-01-05"), list[0].Time, "rows are sorted newest first")
+		assert.Equal(t, -0.02, list[0].Value)
+		assert.Equal(t, 0.01, list[1].Value)
+	})
+
+	t.Run("csv prices with configured columns", func(t *testing.T) {
+		provider := portfolio.NewFileReturnsProvider(fstest.MapFS{
+			"A.csv": {Data: []byte("Open,Close,Day\n1,100,01/04/2021\n1,110,01/05/2021\n1,99,01/06/2021\n")},
+		})
+		provider.DateColumn, provider.ValueColumn = "Day", "Close"
+		provider.Prices = true
+		list, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "A"})
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, date("2021-01-06"), list[0].Time)
+		assert.InDelta(t, -0.1, list[0].Value, 1e-9)
+		assert.InDelta(t, 0.1, list[1].Value, 1e-9)
+	})
+
+	t.Run("csv with other columns", func(t *testing.T) {
+		provider := portfolio.NewFileReturnsProvider(fstest.MapFS{
+			"A.csv": {Data: []byte("Date,Symbol,Volume,Close\n2021-01-04,A,1000,100\n2021-01-05,A,,110\n2021-01-06,A,1200,99\n")},
+		})
+		provider.ValueColumn = "Close"
+		provider.Prices = true
+		list, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "A"})
+		require.NoError(t, err)
+		require.Len(t, list, 2, "the missing volume does not drop the close on 2021-01-05")
+		assert.InDelta(t, -0.1, list[0].Value, 1e-9)
+		assert.InDelta(t, 0.1, list[1].Value, 1e-9)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		provider := portfolio.NewFileReturnsProvider(fstest.MapFS{
+			"A.csv": {Data: []byte("Date,A\n2021-01-01,0.01\n2021-01-04,banana\n")},
+		})
+		_, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "A"})
+		assert.ErrorContains(t, err, "A.csv")
+		assert.ErrorContains(t, err, "line 3")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		provider := portfolio.NewFileReturnsProvider(fstest.MapFS{})
+		_, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "A"})
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		dir := fstest.MapFS{
+			"A.json": {Data: []byte(`[{"time":"2021-01-04T00:00:00Z","value":0.01}]`)},
+		}
+		provider := portfolio.NewFileReturnsProvider(dir)
+		first, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "A"})
+		require.NoError(t, err)
+		delete(dir, "A.json")
+		second, err := provider.ComponentReturnsList(ctx, portfolio.Component{ID: "A"})
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+}
+
+func date(str string) time.Time {
+	d, _ := time.Parse(time.DateOnly, str)
+	return d
+}
@@ -85,11 +85,18 @@ func ParseDocuments(r io.Reader) ([]Document, error) {
 		}
 
 		document.Spec.setDefaultPolicyWeightAlgorithm()
-		if document.Spec.Policy.WeightsAlgorithm == allocation.ConstantWeightsAlgorithmName {
+		if !slices.Contains(allocation.AlgorithmNames(allocation.NewDefaultAlgorithmsList()), document.Spec.Policy.WeightsAlgorithm) {
+			return result, fmt.Errorf("unknown weights algorithm %q", document.Spec.Policy.WeightsAlgorithm)
+		}
+		switch document.Spec.Policy.WeightsAlgorithm {
+		case allocation.ConstantWeightsAlgorithmName, allocation.BlackLittermanAlgorithmName:
 			if len(document.Spec.Policy.Weights) != len(document.Spec.Assets) {
 				return result, errAssetAndWeightsLenMismatch(&document.Spec)
 			}
 		}
+		if _, err := document.Spec.BlackLittermanViews(); err != nil {
+			return result, err
+		}
 		result = append(result, document)
 	}
 }
@@ -102,11 +109,14 @@ func (pf *Specification) RemoveAsset(index int) error {
 	return nil
 }
 
-func (pf *Specification) Backtest(ctx context.Context, assets returns.Table, alg allocation.Algorithm) (backtest.Result, error) {
-	return pf.BacktestWithStartAndEndTime(ctx, time.Time{}, time.Time{}, assets, alg)
+func (pf *Specification) Backtest(ctx context.Context, assets returns.Table, alg allocation.Algorithm, opts ...backtest.Option) (backtest.Result, error) {
+	return pf.BacktestWithStartAndEndTime(ctx, time.Time{}, time.Time{}, assets, alg, opts...)
 }
 
 func (pf *Specification) setDefaultPolicyWeightAlgorithm() {
+	if pf.Policy.WeightsAlgorithm != "" {
+		return
+	}
 	if len(pf.Policy.Weights) > 0 {
 		pf.Policy.WeightsAlgorithm = (*allocation.ConstantWeights)(nil).Name()
 	} else {
@@ -114,7 +124,7 @@ func (pf *Specification) setDefaultPolicyWeightAlgorithm() {
 	}
 }
 
-func (pf *Specification) BacktestWithStartAndEndTime(ctx context.Context, start, end time.Time, assets returns.Table, alg allocation.Algorithm) (backtest.Result, error) {
+func (pf *Specification) BacktestWithStartAndEndTime(ctx context.Context, start, end time.Time, assets returns.Table, alg allocation.Algorithm, opts ...backtest.Option) (backtest.Result, error) {
 	if alg == nil {
 		var err error
 		alg, err = pf.Algorithm(nil)
@@ -122,20 +132,58 @@ func (pf *Specification) BacktestWithStartAndEndTime(ctx context.Context, start,
 			return backtest.Result{}, err
 		}
 	}
+	if pf.Policy.Leverage {
+		// options are applied in order so a backtest.WithLeverage option with financing returns takes precedence
+		opts = append([]backtest.Option{backtest.WithLeverage(nil)}, opts...)
+	}
+	rebalance := pf.Policy.RebalancingInterval.CheckFunction()
+	if pf.Policy.RebalancingThreshold.IsSet() {
+		switch pf.Policy.RebalancingInterval {
+		case backtestconfig.IntervalNever, "":
+			rebalance = backtestconfig.Daily()
+		}
+		opts = append([]backtest.Option{backtest.WithDriftTrigger(pf.Policy.RebalancingThreshold.Exceeded)}, opts...)
+	}
 	return backtest.Run(ctx, end, start, assets, alg,
 		pf.Policy.WeightsAlgorithmLookBack,
 		pf.Policy.WeightsUpdatingInterval.CheckFunction(),
-		pf.Policy.RebalancingInterval.CheckFunction(),
+		rebalance,
+		opts...,
 	)
 }
 
 type Policy struct {
 	RebalancingInterval backtestconfig.Interval `yaml:"rebalancing_interval,omitempty"                    bson:"rebalancing_interval"`
 
+	// RebalancingThreshold restricts rebalancing to days when an asset has drifted outside the tolerance band.
+	// When RebalancingInterval is not set or is Never, drift is checked daily;
+	// so Never with a threshold rebalances only when an asset drifts outside the band.
+	RebalancingThreshold backtestconfig.Threshold `yaml:"rebalancing_threshold,omitempty" bson:"rebalancing_threshold"`
+
 	Weights                  []float64               `yaml:"weights,omitempty"                            bson:"weights"`
 	WeightsAlgorithm         string                  `yaml:"weights_algorithm,omitempty"                  bson:"weights_algorithm"`
 	WeightsAlgorithmLookBack backtestconfig.Window   `yaml:"weights_algorithm_look_back_window,omitempty" bson:"weights_algorithm_look_back_window"`
 	WeightsUpdatingInterval  backtestconfig.Interval `yaml:"weights_updating_interval,omitempty"          bson:"weights_updating_interval"`
+
+	// MinimumWeights, MaximumWeights, and WeightGroups constrain the weights found by optimizing algorithms.
+	// See Specification.WeightConstraints.
+	MinimumWeights []float64     `yaml:"minimum_weights,omitempty" bson:"minimum_weights"`
+	MaximumWeights []float64     `yaml:"maximum_weights,omitempty" bson:"maximum_weights"`
+	WeightGroups   []WeightGroup `yaml:"weight_groups,omitempty"   bson:"weight_groups"`
+
+	// Views are investor views blended with the equilibrium returns implied by Weights
+	// by the Black-Litterman weights algorithm. See Specification.BlackLittermanViews.
+	Views []View `yaml:"views,omitempty" bson:"views"`
+	// Leverage backtests with backtest.WithLeverage so the weights are not scaled to sum to one.
+	// Weights are then percentages of portfolio value: [150, -50] is 150% long the first asset and 50% short the second.
+	// Cash earns nothing unless financing returns are passed to Specification.Backtest with backtest.WithLeverage.
+	Leverage bool `yaml:"leverage,omitempty" bson:"leverage"`
+
+	// RiskFreeReturn is the annualized risk-free return used by the Maximum Sharpe Ratio weights algorithm.
+	RiskFreeReturn float64 `yaml:"risk_free_return,omitempty" bson:"risk_free_return"`
+	// TargetVolatility is the annualized volatility used by the Target Volatility weights algorithm.
+	// When it is not set allocation.DefaultTargetVolatility is used.
+	TargetVolatility float64 `yaml:"target_volatility,omitempty" bson:"target_volatility"`
 }
 
 // Validate does some simple validations.
@@ -145,9 +193,39 @@ func (pf *Specification) Validate() error {
 	for _, asset := range pf.Assets {
 		list = append(list, asset.Validate())
 	}
+	list = append(list, pf.Policy.Validate())
+	if _, err := pf.WeightConstraints(); err != nil {
+		list = append(list, err)
+	}
+	for _, alg := range allocation.NewDefaultAlgorithmsList() {
+		if alg.Name() == pf.Policy.WeightsAlgorithm {
+			list = append(list, pf.errUnsupportedConstraints(alg))
+		}
+	}
+	if _, err := pf.BlackLittermanViews(); err != nil {
+		list = append(list, err)
+	}
 	return errors.Join(list...)
 }
 
+// Validate checks the intervals, look back window, and rebalancing threshold.
+func (policy Policy) Validate() error {
+	return errors.Join(
+		policy.RebalancingInterval.Validate(),
+		policy.RebalancingThreshold.Validate(),
+		policy.WeightsAlgorithmLookBack.Validate(),
+		policy.WeightsUpdatingInterval.Validate(),
+		policy.validateTargetVolatility(),
+	)
+}
+
+func (policy Policy) validateTargetVolatility() error {
+	if policy.TargetVolatility < 0 {
+		return fmt.Errorf("target volatility %v must not be negative", policy.TargetVolatility)
+	}
+	return nil
+}
+
 func (pf *Specification) filterEmptyAssetIDs() {
 	filtered := pf.Assets[:0]
 	for _, asset := range pf.Assets {
@@ -168,7 +246,30 @@ func (pf *Specification) Algorithm(algorithmOptions []allocation.Algorithm) (all
 			continue
 		}
 		if se, ok := alg.(allocation.WeightSetter); ok {
-			se.SetWeights(slices.Clone(pf.Policy.Weights))
+			se.SetWeights(pf.policyWeights())
+		}
+		if err := pf.errUnsupportedConstraints(alg); err != nil {
+			return nil, err
+		}
+		if cs, ok := alg.(allocation.ConstraintSetter); ok {
+			constraints, err := pf.WeightConstraints()
+			if err != nil {
+				return nil, err
+			}
+			cs.SetConstraints(constraints)
+		}
+		if rs, ok := alg.(allocation.RiskFreeReturnSetter); ok {
+			rs.SetRiskFreeReturn(pf.Policy.RiskFreeReturn)
+		}
+		if ts, ok := alg.(allocation.TargetVolatilitySetter); ok {
+			ts.SetTargetVolatility(pf.Policy.TargetVolatility)
+		}
+		if vs, ok := alg.(allocation.ViewSetter); ok {
+			views, err := pf.BlackLittermanViews()
+			if err != nil {
+				return nil, err
+			}
+			vs.SetViews(views)
 		}
 		return alg, nil // algorithm is known
 	}
@@ -176,6 +277,17 @@ func (pf *Specification) Algorithm(algorithmOptions []allocation.Algorithm) (all
 	return nil, errors.New("unknown algorithm")
 }
 
+// policyWeights returns a copy of the policy weights. When the policy is levered they are converted from percentages to fractions.
+func (pf *Specification) policyWeights() []float64 {
+	weights := slices.Clone(pf.Policy.Weights)
+	if pf.Policy.Leverage {
+		for i := range weights {
+			weights[i] /= 100
+		}
+	}
+	return weights
+}
+
 func errAssetAndWeightsLenMismatch(spec *Specification) error {
 	return fmt.Errorf("expected the number of policy weights to be the same as the number of assets got %d but expected %d", len(spec.Policy.Weights), len(spec.Assets))
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -11,6 +12,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/allocation"
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/portfoliotest"
 	"github.com/portfoliotree/portfolio/returns"
 )
 
@@ -107,6 +112,36 @@ func TestParse(t *testing.T) {
 			SpecYAML:            `{type: Portfolio, metadata: {benchmark: []}}`,
 			ErrorStringContains: "wrong YAML type:",
 		},
+		{
+			Name: "unknown weights algorithm",
+			// language=yaml
+			SpecYAML:            `{type: Portfolio, spec: {assets: ["a", "b"], policy: {weights_algorithm: Minimum Varience}}}`,
+			ErrorStringContains: `unknown weights algorithm "Minimum Varience"`,
+		},
+		{
+			Name: "Black-Litterman requires policy weights",
+			// language=yaml
+			SpecYAML:            `{type: Portfolio, spec: {assets: ["a", "b"], policy: {weights_algorithm: Black-Litterman}}}`,
+			ErrorStringContains: "expected the number of policy weights to be the same as the number of assets",
+		},
+		{
+			Name: "view asset is not in the portfolio",
+			// language=yaml
+			SpecYAML:            `{type: Portfolio, spec: {assets: ["a", "b"], policy: {weights: [0.5, 0.5], views: [{assets: ["c"], return: 0.02, confidence: 0.5}]}}}`,
+			ErrorStringContains: `view 0: asset "c" is not in the portfolio`,
+		},
+		{
+			Name: "view confidence is out of range",
+			// language=yaml
+			SpecYAML:            `{type: Portfolio, spec: {assets: ["a", "b"], policy: {weights: [0.5, 0.5], views: [{assets: ["a"], relative_to: ["b"], return: 0.02, confidence: 2}]}}}`,
+			ErrorStringContains: "view 0: view confidence 2 must be greater than 0 and at most 1",
+		},
+		{
+			Name: "view asset is on both sides",
+			// language=yaml
+			SpecYAML:            `{type: Portfolio, spec: {assets: ["a", "b"], policy: {weights: [0.5, 0.5], views: [{assets: ["a"], relative_to: ["a"], return: 0.02, confidence: 0.5}]}}}`,
+			ErrorStringContains: `asset "a" is listed more than once`,
+		},
 	} {
 		t.Run(tt.Name, func(t *testing.T) {
 			p, err := portfolio.ParseOneDocument(tt.SpecYAML)
@@ -131,7 +166,7 @@ spec:
   assets: [ACWI, AGG]
   policy:
     weights: [60, 40]
-    weights_algorithm: ConstantWeights
+    weights_algorithm: Constant Weights
     rebalancing_interval: Quarterly
 `
 
@@ -248,6 +283,30 @@ func Test_Portfolio_Validate(t *testing.T) {
 			},
 			ExpectErr: true,
 		},
+		{
+			Name: "negative rebalancing threshold",
+			Portfolio: portfolio.Document{
+				Type: "Portfolio",
+				Spec: portfolio.Specification{
+					Policy: portfolio.Policy{
+						RebalancingThreshold: backtestconfig.Threshold{Absolute: -0.05},
+					},
+				},
+			},
+			ExpectErr: true,
+		},
+		{
+			Name: "unknown rebalancing interval",
+			Portfolio: portfolio.Document{
+				Type: "Portfolio",
+				Spec: portfolio.Specification{
+					Policy: portfolio.Policy{
+						RebalancingInterval: "Fortnightly",
+					},
+				},
+			},
+			ExpectErr: true,
+		},
 	} {
 		t.Run(tt.Name, func(t *testing.T) {
 			err := tt.Portfolio.Validate()
@@ -260,6 +319,280 @@ func Test_Portfolio_Validate(t *testing.T) {
 	}
 }
 
+func TestPortfolio_Backtest_rebalancing_threshold(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets: [ACWI, AGG]
+  policy:
+    weights: [60, 40]
+    rebalancing_interval: Quarterly
+    rebalancing_threshold: {absolute: 0.05}
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+	require.NoError(t, pf.Validate())
+	assert.Equal(t, backtestconfig.Threshold{Absolute: 0.05}, pf.Spec.Policy.RebalancingThreshold)
+
+	ctx := context.Background()
+	assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, pf.Spec.Assets...)
+	require.NoError(t, err)
+
+	withThreshold, err := pf.Spec.Backtest(ctx, assets, nil)
+	require.NoError(t, err)
+
+	pf.Spec.Policy.RebalancingThreshold = backtestconfig.Threshold{}
+	withoutThreshold, err := pf.Spec.Backtest(ctx, assets, nil)
+	require.NoError(t, err)
+
+	assert.Less(t, len(withThreshold.RebalanceTimes), len(withoutThreshold.RebalanceTimes))
+	for _, trade := range withThreshold.Trades {
+		if trade.Executed {
+			assert.Equal(t, backtest.TradeReasonThreshold, trade.Reason)
+		}
+	}
+
+	t.Run("never checks drift daily", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Policy.RebalancingThreshold = backtestconfig.Threshold{Absolute: 0.05}
+		spec.Policy.RebalancingInterval = backtestconfig.IntervalNever
+		never, err := spec.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		spec.Policy.RebalancingInterval = ""
+		notSet, err := spec.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		assert.NotEmpty(t, never.RebalanceTimes)
+		assert.Equal(t, notSet.RebalanceTimes, never.RebalanceTimes)
+	})
+}
+
+func TestPortfolio_Backtest_leverage(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets: [SPY, AGG]
+  policy:
+    weights: [150, -20]
+    leverage: true
+    rebalancing_interval: Monthly
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+	assert.Equal(t, allocation.ConstantWeightsAlgorithmName, pf.Spec.Policy.WeightsAlgorithm)
+
+	ctx := context.Background()
+	assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, pf.Spec.Assets...)
+	require.NoError(t, err)
+
+	result, err := pf.Spec.Backtest(ctx, assets, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.5, -0.2}, result.FinalPolicyWeights, "percentages are converted to fractions")
+	require.NotEmpty(t, result.RebalanceTimes)
+	index := slices.IndexFunc(result.ReturnsTable.Times(), result.RebalanceTimes[0].Equal)
+	assert.InDelta(t, 1.7, result.GrossExposures[index], 1e-9)
+	assert.InDelta(t, 1.3, result.NetExposures[index], 1e-9)
+
+	t.Run("financing returns", func(t *testing.T) {
+		financing := returns.List{{Time: assets.LastTime(), Value: 0.01}}
+		financed, err := pf.Spec.Backtest(ctx, assets, nil, backtest.WithLeverage(financing))
+		require.NoError(t, err)
+		assert.NotEqual(t, result.Returns()[0].Value, financed.Returns()[0].Value)
+	})
+}
+
+func TestPortfolio_Backtest_optimizing_algorithms(t *testing.T) {
+	for _, name := range []string{
+		allocation.MinimumVarianceAlgorithmName,
+		allocation.MaximumSharpeRatioAlgorithmName,
+		allocation.TargetVolatilityAlgorithmName,
+		allocation.HierarchicalRiskParityAlgorithmName,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// language=yaml
+			specYAML := fmt.Sprintf(`---
+type: Portfolio
+spec:
+  assets: [ACWI, AGG]
+  policy:
+    weights_algorithm: %s
+    weights_algorithm_look_back_window: 1 Year
+    weights_updating_interval: Quarterly
+    rebalancing_interval: Monthly
+`, name)
+			pf, err := portfolio.ParseOneDocument(specYAML)
+			require.NoError(t, err)
+			assert.Equal(t, name, pf.Spec.Policy.WeightsAlgorithm)
+
+			ctx := context.Background()
+			assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, pf.Spec.Assets...)
+			require.NoError(t, err)
+
+			result, err := pf.Spec.Backtest(ctx, assets, nil)
+			require.NoError(t, err)
+			assert.NotEmpty(t, result.PolicyUpdateTimes)
+			assert.InDelta(t, 1, result.FinalPolicyWeights[0]+result.FinalPolicyWeights[1], 0.0001)
+		})
+	}
+}
+
+func TestSpecification_Algorithm_policy_parameters(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets: [ACWI, AGG]
+  policy:
+    weights_algorithm: Maximum Sharpe Ratio
+    risk_free_return: 0.03
+    target_volatility: 0.08
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+	require.NoError(t, pf.Spec.Validate())
+
+	alg, err := pf.Spec.Algorithm(nil)
+	require.NoError(t, err)
+	require.IsType(t, &allocation.MaximumSharpeRatio{}, alg)
+	assert.Equal(t, 0.03, alg.(*allocation.MaximumSharpeRatio).RiskFreeReturn)
+
+	spec := pf.Spec
+	spec.Policy.WeightsAlgorithm = allocation.TargetVolatilityAlgorithmName
+	alg, err = spec.Algorithm(nil)
+	require.NoError(t, err)
+	require.IsType(t, &allocation.TargetVolatility{}, alg)
+	assert.Equal(t, 0.08, alg.(*allocation.TargetVolatility).AnnualizedVolatility)
+
+	spec.Policy.TargetVolatility = -0.1
+	assert.ErrorContains(t, spec.Validate(), "target volatility -0.1 must not be negative")
+}
+
+func TestPortfolio_Backtest_weight_constraints(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets: [ACWI, AGG, SPY]
+  policy:
+    weights_algorithm: Minimum Variance
+    weights_algorithm_look_back_window: 1 Year
+    weights_updating_interval: Quarterly
+    rebalancing_interval: Monthly
+    maximum_weights: [1, 0.5, 1]
+    weight_groups:
+      - name: Equity
+        assets: [ACWI, SPY]
+        minimum: 0.5
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+	require.Len(t, pf.Spec.Policy.WeightGroups, 1)
+	assert.Equal(t, 1.0, pf.Spec.Policy.WeightGroups[0].Maximum)
+
+	constraints, err := pf.Spec.WeightConstraints()
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, constraints.Groups[0].Assets)
+
+	ctx := context.Background()
+	assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, pf.Spec.Assets...)
+	require.NoError(t, err)
+
+	result, err := pf.Spec.Backtest(ctx, assets, nil)
+	require.NoError(t, err)
+	ws := result.FinalPolicyWeights
+	assert.LessOrEqual(t, ws[1], 0.5+1e-6)
+	assert.GreaterOrEqual(t, ws[0]+ws[2], 0.5-1e-6)
+
+	t.Run("infeasible", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Policy.MaximumWeights = []float64{0.2, 0.2, 0.2}
+		assert.ErrorContains(t, spec.Validate(), "infeasible weight constraints")
+		_, err := spec.Algorithm(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown group asset", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Policy.WeightGroups = []portfolio.WeightGroup{{Name: "Bonds", Assets: []string{"BND"}, Maximum: 1}}
+		assert.ErrorContains(t, spec.Validate(), `asset "BND" is not in the portfolio`)
+	})
+
+	t.Run("algorithm does not support constraints", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Policy.WeightsAlgorithm = allocation.HierarchicalRiskParityAlgorithmName
+		assert.ErrorContains(t, spec.Validate(), `weights algorithm "Hierarchical Risk Parity" does not support weight constraints`)
+		_, err := spec.Backtest(ctx, assets, nil)
+		assert.ErrorContains(t, err, "does not support weight constraints")
+	})
+}
+
+func TestPortfolio_Backtest_black_litterman(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets: [ACWI, AGG]
+  policy:
+    weights_algorithm: Black-Litterman
+    weights: [0.6, 0.4]
+    weights_algorithm_look_back_window: 1 Year
+    weights_updating_interval: Quarterly
+    rebalancing_interval: Monthly
+    views:
+      - assets: [ACWI]
+        relative_to: [AGG]
+        return: 0.02
+        confidence: 0.5
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+	assert.Equal(t, allocation.BlackLittermanAlgorithmName, pf.Spec.Policy.WeightsAlgorithm)
+
+	views, err := pf.Spec.BlackLittermanViews()
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, []float64{1, -1}, views[0].Weights)
+
+	ctx := context.Background()
+	assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, pf.Spec.Assets...)
+	require.NoError(t, err)
+
+	result, err := pf.Spec.Backtest(ctx, assets, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.PolicyUpdateTimes)
+	assert.InDelta(t, 1, result.FinalPolicyWeights[0]+result.FinalPolicyWeights[1], 0.0001)
+	for _, w := range result.FinalPolicyWeights {
+		assert.GreaterOrEqual(t, w, 0.0, "weights are long-only by default")
+	}
+
+	t.Run("percent weights", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Policy.Weights = []float64{60, 40}
+		percentResult, err := spec.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, result.FinalPolicyWeights, percentResult.FinalPolicyWeights, 0.001, "the views count the same")
+	})
+
+	t.Run("percent weights without views imply the policy weights", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Policy.Weights = []float64{60, 40}
+		spec.Policy.Views = nil
+		result, err := spec.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.6, 0.4}, result.FinalPolicyWeights, 0.001)
+	})
+
+	t.Run("maximum weights", func(t *testing.T) {
+		spec := pf.Spec
+		spec.Policy.Weights = []float64{60, 40}
+		spec.Policy.MaximumWeights = []float64{0.3, 1}
+		result, err := spec.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, result.FinalPolicyWeights[0], 0.3+1e-9)
+	})
+}
+
 func TestPortfolio_RemoveAsset(t *testing.T) {
 	t.Run("nil", func(t *testing.T) {
 		var zero portfolio.Specification
@@ -0,0 +1,211 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// ErrorPortfolioCycle is returned by Resolver when a portfolio (directly or indirectly) holds itself.
+// Path lists the component IDs from the outermost portfolio to the repeated portfolio.
+type ErrorPortfolioCycle struct {
+	Path []string
+}
+
+func (err ErrorPortfolioCycle) Error() string {
+	return fmt.Sprintf("portfolio cycle detected: %s", strings.Join(err.Path, " -> "))
+}
+
+func (ErrorPortfolioCycle) Is(target error) bool {
+	_, ok := target.(ErrorPortfolioCycle)
+	return ok
+}
+
+// Resolver implements ComponentReturnsProvider for specifications whose assets are other portfolios.
+// A component is a portfolio when its ID matches the hex ID of one of Documents,
+// or when its Type is ComponentTypePortfolio and its ID matches the metadata name of one of Documents.
+// Portfolio returns are the backtest returns of the child portfolio.
+// The returns of all other components are fetched from Provider.
+// Backtest results are cached so each child portfolio is backtested once.
+type Resolver struct {
+	Documents []Document
+	Provider  ComponentReturnsProvider
+
+	mut     sync.Mutex
+	results map[string]backtest.Result
+}
+
+// NewResolver returns a Resolver for documents (for example from WalkDirectoryAndParseSpecificationFiles).
+func NewResolver(provider ComponentReturnsProvider, documents []Document) *Resolver {
+	return &Resolver{
+		Documents: documents,
+		Provider:  provider,
+	}
+}
+
+// Document returns the document referenced by component.
+func (resolver *Resolver) Document(component Component) (Document, bool) {
+	for _, document := range resolver.Documents {
+		if component.ID == "" {
+			break
+		}
+		if !document.ID.IsZero() && document.ID.Hex() == component.ID {
+			return document, true
+		}
+		if component.Type == ComponentTypePortfolio && document.Metadata.Name == component.ID {
+			return document, true
+		}
+	}
+	return Document{}, false
+}
+
+// ComponentReturnsList implements ComponentReturnsProvider.
+func (resolver *Resolver) ComponentReturnsList(ctx context.Context, component Component) (returns.List, error) {
+	return resolver.componentReturnsList(ctx, component, nil)
+}
+
+// ComponentReturnsTable implements ComponentReturnsProvider.
+func (resolver *Resolver) ComponentReturnsTable(ctx context.Context, components ...Component) (returns.Table, error) {
+	return resolver.componentReturnsTable(ctx, components, nil)
+}
+
+// Backtest backtests spec after resolving the returns of its assets.
+func (resolver *Resolver) Backtest(ctx context.Context, spec *Specification) (backtest.Result, error) {
+	return resolver.backtest(ctx, spec, nil)
+}
+
+func (resolver *Resolver) componentReturnsTable(ctx context.Context, components []Component, path []string) (returns.Table, error) {
+	var table returns.Table
+	for _, component := range components {
+		list, err := resolver.componentReturnsList(ctx, component, path)
+		if err != nil {
+			return returns.Table{}, err
+		}
+		table = table.AddColumn(list)
+	}
+	return table, nil
+}
+
+func (resolver *Resolver) componentReturnsList(ctx context.Context, component Component, path []string) (returns.List, error) {
+	document, ok := resolver.Document(component)
+	if !ok {
+		if component.Type == ComponentTypePortfolio {
+			return nil, fmt.Errorf("portfolio %q not found", component.ID)
+		}
+		if resolver.Provider == nil {
+			return nil, fmt.Errorf("no returns provider for component %q", component.ID)
+		}
+		return resolver.Provider.ComponentReturnsList(ctx, component)
+	}
+	result, err := resolver.documentResult(ctx, component.ID, document, path)
+	if err != nil {
+		return nil, err
+	}
+	return result.Returns(), nil
+}
+
+func (resolver *Resolver) documentResult(ctx context.Context, id string, document Document, path []string) (backtest.Result, error) {
+	if slices.Contains(path, id) {
+		return backtest.Result{}, ErrorPortfolioCycle{Path: append(slices.Clone(path), id)}
+	}
+
+	resolver.mut.Lock()
+	result, ok := resolver.results[id]
+	resolver.mut.Unlock()
+	if ok {
+		return result, nil
+	}
+
+	result, err := resolver.backtest(ctx, &document.Spec, append(path, id))
+	if err != nil {
+		return backtest.Result{}, fmt.Errorf("failed to backtest portfolio %q: %w", id, err)
+	}
+
+	resolver.mut.Lock()
+	defer resolver.mut.Unlock()
+	if resolver.results == nil {
+		resolver.results = make(map[string]backtest.Result)
+	}
+	resolver.results[id] = result
+	return result, nil
+}
+
+func (resolver *Resolver) backtest(ctx context.Context, spec *Specification, path []string) (backtest.Result, error) {
+	assets, err := resolver.componentReturnsTable(ctx, spec.Assets, path)
+	if err != nil {
+		return backtest.Result{}, err
+	}
+	return spec.Backtest(ctx, assets, nil)
+}
+
+// Exposure is the fraction of a portfolio's value held in a component.
+type Exposure struct {
+	Component Component `json:"component" bson:"component"`
+	Weight    float64   `json:"weight"    bson:"weight"`
+}
+
+// LookThroughExposures replaces each portfolio asset of spec with the assets it holds
+// and returns the resulting exposures to the underlying components.
+// Weights are the most recent backtest weights of each portfolio scaled to sum to one.
+// Exposures to the same component through different portfolios are added together.
+// The exposures are ordered by first appearance in a depth first walk of the assets.
+func (resolver *Resolver) LookThroughExposures(ctx context.Context, spec *Specification) ([]Exposure, error) {
+	result, err := resolver.Backtest(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	var exposures []Exposure
+	err = resolver.lookThrough(ctx, &exposures, spec, result, 1, nil)
+	return exposures, err
+}
+
+func (resolver *Resolver) lookThrough(ctx context.Context, exposures *[]Exposure, spec *Specification, result backtest.Result, scale float64, path []string) error {
+	ws := currentWeights(result)
+	for i, component := range spec.Assets {
+		weight := scale * ws[i]
+		document, ok := resolver.Document(component)
+		if !ok {
+			addExposure(exposures, component, weight)
+			continue
+		}
+		childResult, err := resolver.documentResult(ctx, component.ID, document, path)
+		if err != nil {
+			return err
+		}
+		if err := resolver.lookThrough(ctx, exposures, &document.Spec, childResult, weight, append(path, component.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func currentWeights(result backtest.Result) []float64 {
+	if len(result.Weights) == 0 {
+		return slices.Clone(result.FinalPolicyWeights)
+	}
+	ws := slices.Clone(result.Weights[0])
+	sum := 0.0
+	for _, w := range ws {
+		sum += w
+	}
+	if sum != 0 {
+		for i := range ws {
+			ws[i] /= sum
+		}
+	}
+	return ws
+}
+
+func addExposure(exposures *[]Exposure, component Component, weight float64) {
+	index := slices.IndexFunc(*exposures, func(e Exposure) bool { return e.Component.ID == component.ID })
+	if index < 0 {
+		*exposures = append(*exposures, Exposure{Component: component, Weight: weight})
+		return
+	}
+	(*exposures)[index].Weight += weight
+}
@@ -0,0 +1,119 @@
+package portfolio_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+)
+
+func TestResolver(t *testing.T) {
+	// language=yaml
+	documentsYAML := `---
+type: Portfolio
+_id: 64b7f7c2e5a1f2a3b4c5d6e7
+metadata:
+  name: Balanced
+spec:
+  assets: [ACWI, AGG]
+  policy:
+    weights: [0.6, 0.4]
+    rebalancing_interval: Quarterly
+---
+type: Portfolio
+metadata:
+  name: Core
+spec:
+  assets:
+    - {id: 64b7f7c2e5a1f2a3b4c5d6e7, type: Portfolio}
+    - AGG
+  policy:
+    weights: [0.5, 0.5]
+    rebalancing_interval: Quarterly
+`
+	documents, err := portfolio.ParseDocuments(strings.NewReader(documentsYAML))
+	require.NoError(t, err)
+	require.Len(t, documents, 2)
+	assert.Equal(t, "64b7f7c2e5a1f2a3b4c5d6e7", documents[0].ID.Hex())
+
+	ctx := context.Background()
+	resolver := portfolio.NewResolver(portfoliotest.ComponentReturnsProvider(), documents)
+
+	t.Run("child returns are backtest returns", func(t *testing.T) {
+		list, err := resolver.ComponentReturnsList(ctx, portfolio.Component{ID: "Balanced", Type: portfolio.ComponentTypePortfolio})
+		require.NoError(t, err)
+
+		child := documents[0].Spec
+		assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, child.Assets...)
+		require.NoError(t, err)
+		result, err := child.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		assert.Equal(t, result.Returns(), list)
+	})
+
+	t.Run("backtest parent", func(t *testing.T) {
+		result, err := resolver.Backtest(ctx, &documents[1].Spec)
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.Returns())
+	})
+
+	t.Run("look through", func(t *testing.T) {
+		exposures, err := resolver.LookThroughExposures(ctx, &documents[1].Spec)
+		require.NoError(t, err)
+		require.Len(t, exposures, 2)
+		assert.Equal(t, "ACWI", exposures[0].Component.ID)
+		assert.Equal(t, "AGG", exposures[1].Component.ID)
+		assert.InDelta(t, 1, exposures[0].Weight+exposures[1].Weight, 1e-9)
+		assert.Greater(t, exposures[1].Weight, 0.5, "AGG is held directly and through the child portfolio")
+	})
+
+	t.Run("name requires the portfolio type", func(t *testing.T) {
+		named := portfolio.NewResolver(portfoliotest.ComponentReturnsProvider(), []portfolio.Document{{Metadata: portfolio.Metadata{Name: "AGG"}}})
+		_, ok := named.Document(portfolio.Component{ID: "AGG"})
+		assert.False(t, ok, "a portfolio named like a ticker must not replace the asset")
+		_, ok = named.Document(portfolio.Component{ID: "AGG", Type: portfolio.ComponentTypePortfolio})
+		assert.True(t, ok)
+	})
+
+	t.Run("missing portfolio", func(t *testing.T) {
+		_, err := resolver.ComponentReturnsList(ctx, portfolio.Component{ID: "64b7f7c2e5a1f2a3b4c5d6e8", Type: portfolio.ComponentTypePortfolio})
+		assert.ErrorContains(t, err, "not found")
+	})
+}
+
+func TestResolver_cycle(t *testing.T) {
+	// language=yaml
+	documentsYAML := `---
+type: Portfolio
+metadata:
+  name: A
+spec:
+  assets:
+    - {id: B, type: Portfolio}
+    - AGG
+---
+type: Portfolio
+metadata:
+  name: B
+spec:
+  assets:
+    - {id: A, type: Portfolio}
+    - ACWI
+`
+	documents, err := portfolio.ParseDocuments(strings.NewReader(documentsYAML))
+	require.NoError(t, err)
+
+	resolver := portfolio.NewResolver(portfoliotest.ComponentReturnsProvider(), documents)
+	_, err = resolver.Backtest(context.Background(), &documents[0].Spec)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, portfolio.ErrorPortfolioCycle{}))
+	var cycle portfolio.ErrorPortfolioCycle
+	require.True(t, errors.As(err, &cycle))
+	assert.Equal(t, []string{"B", "A", "B"}, cycle.Path)
+}
@@ -0,0 +1,202 @@
+package returns
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AlignmentPolicy sets how Alignment handles times where some columns do not have a return.
+type AlignmentPolicy int
+
+const (
+	// AlignIntersect keeps only the times where every column has a return.
+	// Returns on the other times are discarded.
+	AlignIntersect AlignmentPolicy = iota
+	// AlignUnionZeroFill keeps every time and sets missing returns to zero.
+	AlignUnionZeroFill
+	// AlignUnionCompound keeps every time and carries the last observed return forward over a gap.
+	// The next observed return is reduced by the growth carried over the gap, so the compounded return
+	// of the column is unchanged and a backtest never sees a return before it is observed.
+	// Times before the first return, times after the last return, and gaps after a return of -100% or less are zero.
+	AlignUnionCompound
+)
+
+func (policy AlignmentPolicy) String() string {
+	switch policy {
+	case AlignIntersect:
+		return "Intersect"
+	case AlignUnionZeroFill:
+		return "Union Zero Fill"
+	case AlignUnionCompound:
+		return "Union Compound"
+	default:
+		return fmt.Sprintf("AlignmentPolicy(%d)", int(policy))
+	}
+}
+
+// Alignment date-aligns lists of returns into a Table. Unlike Table.AddColumn, it reports what was changed.
+type Alignment struct {
+	Policy AlignmentPolicy
+	// MinimumCoverage drops columns with returns for less than this fraction of all the times.
+	MinimumCoverage float64
+}
+
+// AlignmentReport describes the changes made by Alignment.
+type AlignmentReport struct {
+	// DroppedTimes are times with a return in at least one column that are not in the result. They are sorted newest first.
+	DroppedTimes []time.Time `json:"droppedTimes" bson:"droppedTimes"`
+	// Columns has an entry for each input column.
+	Columns []ColumnAlignment `json:"columns" bson:"columns"`
+}
+
+// ColumnAlignment describes the alignment of one input column.
+type ColumnAlignment struct {
+	// Index is the input index. For Alignment.AlignTables columns are numbered across the tables in order.
+	Index int `json:"index" bson:"index"`
+	// Coverage is the fraction of all the input times where the column has a return.
+	Coverage float64 `json:"coverage" bson:"coverage"`
+	// Filled is the number of returns set by the policy.
+	Filled int `json:"filled" bson:"filled"`
+	// Discarded is the number of returns that are not in the result.
+	Discarded int `json:"discarded" bson:"discarded"`
+	// Dropped is true when Coverage is less than MinimumCoverage. The column is not in the result.
+	Dropped bool `json:"dropped" bson:"dropped"`
+}
+
+// Align returns a Table with a column for each list that was not dropped.
+func (alignment Alignment) Align(lists []List) (Table, AlignmentReport) {
+	lists = slices.Clone(lists)
+	for i := range lists {
+		lists[i] = slices.Clone(lists[i])
+		lists[i].Sort()
+	}
+
+	allTimes := unionTimes(lists)
+	report := AlignmentReport{Columns: make([]ColumnAlignment, len(lists))}
+	kept := make([]List, 0, len(lists))
+	for i, list := range lists {
+		column := ColumnAlignment{Index: i}
+		if len(allTimes) > 0 {
+			column.Coverage = float64(len(list)) / float64(len(allTimes))
+		}
+		column.Dropped = column.Coverage < alignment.MinimumCoverage
+		if column.Dropped {
+			column.Discarded = len(list)
+		} else {
+			kept = append(kept, list)
+		}
+		report.Columns[i] = column
+	}
+
+	var times []time.Time
+	if alignment.Policy == AlignIntersect {
+		times = intersectTimes(kept)
+	} else {
+		times = unionTimes(kept)
+	}
+	for _, tm := range allTimes {
+		if !containsTime(times, tm) {
+			report.DroppedTimes = append(report.DroppedTimes, tm)
+		}
+	}
+
+	values := make([][]float64, 0, len(kept))
+	k := 0
+	for i := range report.Columns {
+		if report.Columns[i].Dropped {
+			continue
+		}
+		column, filled, discarded := alignColumn(kept[k], times, alignment.Policy == AlignUnionCompound)
+		report.Columns[i].Filled, report.Columns[i].Discarded = filled, discarded
+		values = append(values, column)
+		k++
+	}
+	return NewTableFromValues(times, values), report
+}
+
+// AlignTables aligns the columns of all the tables and returns a table for each input table.
+// Dropped columns are removed from their table.
+func (alignment Alignment) AlignTables(tables ...Table) ([]Table, AlignmentReport) {
+	var lists []List
+	for _, table := range tables {
+		lists = append(lists, table.Lists()...)
+	}
+	aligned, report := alignment.Align(lists)
+	result := make([]Table, len(tables))
+	input, output := 0, 0
+	for i, table := range tables {
+		values := make([][]float64, 0, table.NumberOfColumns())
+		for range table.values {
+			if !report.Columns[input].Dropped {
+				values = append(values, aligned.values[output])
+				output++
+			}
+			input++
+		}
+		result[i] = NewTableFromValues(aligned.times, values)
+	}
+	return result, report
+}
+
+func alignColumn(list List, times []time.Time, carry bool) (values []float64, filled, discarded int) {
+	values = make([]float64, len(times))
+	var (
+		carried float64
+		growth  = 1.0
+	)
+	li := len(list) - 1 // walk both list and times from oldest to newest
+	for ti := len(times) - 1; ti >= 0; ti-- {
+		tm := times[ti]
+		for li >= 0 && list[li].Time.Before(tm) {
+			discarded++
+			li--
+		}
+		if li < 0 || !list[li].Time.Equal(tm) {
+			filled++
+			if carry && li >= 0 {
+				values[ti] = carried
+				growth *= 1 + carried
+			}
+			continue
+		}
+		values[ti] = list[li].Value
+		if growth != 1 {
+			values[ti] = (1+list[li].Value)/growth - 1
+		}
+		carried, growth = list[li].Value, 1
+		if carried <= -1 {
+			carried = 0
+		}
+		li--
+	}
+	discarded += li + 1
+	return values, filled, discarded
+}
+
+func unionTimes(lists []List) []time.Time {
+	var times []time.Time
+	for _, list := range lists {
+		times = append(times, list.Times()...)
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return b.Compare(a) })
+	return slices.CompactFunc(times, time.Time.Equal)
+}
+
+func intersectTimes(lists []List) []time.Time {
+	if len(lists) == 0 {
+		return nil
+	}
+	times := lists[0].Times()
+	for _, list := range lists[1:] {
+		other := list.Times()
+		times = slices.DeleteFunc(times, func(tm time.Time) bool { return !containsTime(other, tm) })
+	}
+	return times
+}
+
+// containsTime expects times to be sorted newest first.
+func containsTime(times []time.Time, tm time.Time) bool {
+	_, found := slices.BinarySearchFunc(times, tm, func(et, t time.Time) int { return t.Compare(et) })
+	return found
+}
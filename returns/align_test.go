@@ -0,0 +1,100 @@
+package returns_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/internal/fixtures"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestAlignment_Align(t *testing.T) {
+	liquid := returns.List{
+		rtn(t, "2021-01-08", 0.01),
+		rtn(t, "2021-01-07", 0.02),
+		rtn(t, "2021-01-06", 0.03),
+		rtn(t, "2021-01-05", 0.04),
+		rtn(t, "2021-01-04", 0.05),
+	}
+	illiquid := returns.List{
+		rtn(t, "2021-01-08", 0.1),
+		rtn(t, "2021-01-06", 0.21),
+		rtn(t, "2021-01-05", 0.3),
+	}
+	sparse := returns.List{
+		rtn(t, "2021-01-07", 0.5),
+	}
+	allTimes := []time.Time{
+		fixtures.T(t, "2021-01-08"),
+		fixtures.T(t, "2021-01-07"),
+		fixtures.T(t, "2021-01-06"),
+		fixtures.T(t, "2021-01-05"),
+		fixtures.T(t, "2021-01-04"),
+	}
+
+	t.Run("intersect", func(t *testing.T) {
+		table, report := returns.Alignment{Policy: returns.AlignIntersect}.Align([]returns.List{liquid, illiquid})
+		assert.Equal(t, []time.Time{fixtures.T(t, "2021-01-08"), fixtures.T(t, "2021-01-06"), fixtures.T(t, "2021-01-05")}, table.Times())
+		assert.Equal(t, []float64{0.01, 0.03, 0.04}, table.List(0).Values())
+		assert.Equal(t, []time.Time{fixtures.T(t, "2021-01-07"), fixtures.T(t, "2021-01-04")}, report.DroppedTimes)
+		require.Len(t, report.Columns, 2)
+		assert.Equal(t, returns.ColumnAlignment{Index: 0, Coverage: 1, Discarded: 2}, report.Columns[0])
+		assert.Equal(t, returns.ColumnAlignment{Index: 1, Coverage: 0.6}, report.Columns[1])
+	})
+
+	t.Run("union zero fill", func(t *testing.T) {
+		table, report := returns.Alignment{Policy: returns.AlignUnionZeroFill}.Align([]returns.List{liquid, illiquid})
+		assert.Equal(t, allTimes, table.Times())
+		assert.Equal(t, []float64{0.1, 0, 0.21, 0.3, 0}, table.List(1).Values())
+		assert.Empty(t, report.DroppedTimes)
+		assert.Equal(t, 2, report.Columns[1].Filled)
+	})
+
+	t.Run("union compound", func(t *testing.T) {
+		table, report := returns.Alignment{Policy: returns.AlignUnionCompound}.Align([]returns.List{liquid, illiquid})
+		assert.Equal(t, allTimes, table.Times())
+		assert.InDeltaSlice(t, []float64{1.1/1.21 - 1, 0.21, 0.21, 0.3, 0}, table.List(1).Values(), 1e-12, "the last return is carried over the gap and the return after the gap is reduced by the carried growth")
+		assert.Equal(t, liquid.Values(), table.List(0).Values())
+		assert.Equal(t, 2, report.Columns[1].Filled)
+
+		zeroFill, _ := returns.Alignment{Policy: returns.AlignUnionZeroFill}.Align([]returns.List{liquid, illiquid})
+		assert.NotEqual(t, zeroFill.List(1).Values(), table.List(1).Values())
+		assert.InDelta(t, illiquid.TimeWeightedReturn(), table.List(1).TimeWeightedReturn(), 1e-12, "no growth is lost or added")
+
+		for i, column := range []returns.List{liquid, illiquid} {
+			first := column[len(column)-1].Time
+			for _, r := range table.List(i) {
+				if r.Time.Before(first) {
+					assert.Zero(t, r.Value, "column %d has a value on %s before its first return", i, r.Time.Format(time.DateOnly))
+				}
+			}
+		}
+	})
+
+	t.Run("minimum coverage", func(t *testing.T) {
+		table, report := returns.Alignment{Policy: returns.AlignIntersect, MinimumCoverage: 0.5}.Align([]returns.List{liquid, sparse, illiquid})
+		assert.Equal(t, 2, table.NumberOfColumns())
+		assert.Equal(t, 3, table.NumberOfRows(), "the sparse column does not shrink the table")
+		assert.True(t, report.Columns[1].Dropped)
+		assert.Equal(t, 1, report.Columns[1].Discarded)
+		assert.InDelta(t, 0.2, report.Columns[1].Coverage, 1e-12)
+		assert.False(t, report.Columns[2].Dropped)
+	})
+
+	t.Run("tables", func(t *testing.T) {
+		tables, report := returns.Alignment{Policy: returns.AlignUnionZeroFill, MinimumCoverage: 0.7}.AlignTables(
+			returns.NewTable([]returns.List{liquid}),
+			returns.NewTable([]returns.List{illiquid}),
+			returns.NewTable([]returns.List{liquid}),
+		)
+		require.Len(t, tables, 3)
+		require.Len(t, report.Columns, 3)
+		assert.True(t, report.Columns[1].Dropped)
+		assert.Equal(t, 1, tables[0].NumberOfColumns())
+		assert.Equal(t, 0, tables[1].NumberOfColumns())
+		assert.Equal(t, liquid.Values(), tables[2].List(0).Values())
+	})
+}
@@ -0,0 +1,116 @@
+package returns
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+// Splice records where proxy returns were joined to a list by Backfill or BackfillWithRegression.
+type Splice struct {
+	// Time is the oldest time of the original returns. Returns before Time come from the proxy.
+	Time time.Time `json:"time" bson:"time"`
+	// Backfilled is the number of proxy returns added.
+	Backfilled int `json:"backfilled" bson:"backfilled"`
+	// Scale multiplies the proxy returns so their volatility matches the original returns where they overlap.
+	// It is one when volatility is not scaled.
+	Scale float64 `json:"scale" bson:"scale"`
+	// Regression is set by BackfillWithRegression. It does not include residuals.
+	Regression *calculate.Regression `json:"regression,omitempty" bson:"regression,omitempty"`
+}
+
+// Backfill adds the proxy returns from before the oldest return in list.
+// When scaleVolatility is true, the proxy returns are scaled by the ratio of the volatility of list
+// and the volatility of proxy where they overlap.
+func (list List) Backfill(proxy List, scaleVolatility bool) (List, Splice, error) {
+	if len(list) == 0 {
+		return nil, Splice{}, ErrorNoReturns{}
+	}
+	list, proxy = sortedClone(list), sortedClone(proxy)
+	splice := Splice{Time: list.FirstTime(), Scale: 1}
+	if scaleVolatility {
+		var listValues, proxyValues []float64
+		for _, r := range list {
+			if value, ok := proxy.Value(r.Time); ok {
+				listValues = append(listValues, r.Value)
+				proxyValues = append(proxyValues, value)
+			}
+		}
+		if len(listValues) < 2 {
+			return nil, Splice{}, errors.New("not enough overlapping returns to scale the proxy volatility")
+		}
+		if risk := calculate.RiskFromStdDev(proxyValues); risk != 0 {
+			splice.Scale = calculate.RiskFromStdDev(listValues) / risk
+		}
+	}
+	for _, r := range proxy {
+		if !r.Time.Before(splice.Time) {
+			continue
+		}
+		list = append(list, New(r.Time, r.Value*splice.Scale))
+		splice.Backfilled++
+	}
+	return list, splice, nil
+}
+
+// BackfillWithRegression regresses list on the factors where they overlap and adds the fitted returns
+// from before the oldest return in list. When scaleVolatility is true, the fitted returns are scaled
+// so their volatility matches list where they overlap.
+func (list List) BackfillWithRegression(factors Table, scaleVolatility bool) (List, Splice, error) {
+	if len(list) == 0 {
+		return nil, Splice{}, ErrorNoReturns{}
+	}
+	list = sortedClone(list)
+	splice := Splice{Time: list.FirstTime(), Scale: 1}
+
+	var (
+		y  []float64
+		xs = make([][]float64, factors.NumberOfColumns())
+	)
+	for _, r := range list {
+		row, ok := factors.Row(r.Time)
+		if !ok {
+			continue
+		}
+		y = append(y, r.Value)
+		for j := range xs {
+			xs[j] = append(xs[j], row[j])
+		}
+	}
+	regression, err := calculate.OrdinaryLeastSquares(y, xs)
+	if err != nil {
+		return nil, Splice{}, err
+	}
+	if scaleVolatility {
+		fitted := make([]float64, len(y))
+		for i := range y {
+			fitted[i] = y[i] - regression.Residuals[i]
+		}
+		if risk := calculate.RiskFromStdDev(fitted); risk != 0 {
+			splice.Scale = calculate.RiskFromStdDev(y) / risk
+		}
+	}
+	regression.Residuals = nil
+	splice.Regression = &regression
+
+	row := make([]float64, factors.NumberOfColumns())
+	for i, tm := range factors.Times() {
+		if !tm.Before(splice.Time) {
+			continue
+		}
+		for j := range row {
+			row[j] = factors.values[j][i]
+		}
+		list = append(list, New(tm, regression.Predict(row)*splice.Scale))
+		splice.Backfilled++
+	}
+	return list, splice, nil
+}
+
+func sortedClone(list List) List {
+	list = slices.Clone(list)
+	list.Sort()
+	return list
+}
@@ -0,0 +1,81 @@
+package returns_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/internal/fixtures"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestList_Backfill(t *testing.T) {
+	asset := returns.List{
+		rtn(t, "2021-01-08", 0.02),
+		rtn(t, "2021-01-07", -0.02),
+		rtn(t, "2021-01-06", 0.04),
+	}
+	proxy := returns.List{
+		rtn(t, "2021-01-08", 0.01),
+		rtn(t, "2021-01-07", -0.01),
+		rtn(t, "2021-01-06", 0.02),
+		rtn(t, "2021-01-05", 0.03),
+		rtn(t, "2021-01-04", -0.01),
+	}
+
+	t.Run("splice", func(t *testing.T) {
+		result, splice, err := asset.Backfill(proxy, false)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.02, -0.02, 0.04, 0.03, -0.01}, result.Values())
+		assert.Equal(t, proxy.Times(), result.Times())
+		assert.Equal(t, returns.Splice{Time: fixtures.T(t, "2021-01-06"), Backfilled: 2, Scale: 1}, splice)
+		assert.Len(t, asset, 3, "the receiver is not modified")
+	})
+
+	t.Run("scaled", func(t *testing.T) {
+		result, splice, err := asset.Backfill(proxy, true)
+		require.NoError(t, err)
+		assert.InDelta(t, 2, splice.Scale, 1e-12)
+		assert.InDeltaSlice(t, []float64{0.02, -0.02, 0.04, 0.06, -0.02}, result.Values(), 1e-12)
+	})
+
+	t.Run("no returns", func(t *testing.T) {
+		_, _, err := returns.List{}.Backfill(proxy, false)
+		assert.ErrorIs(t, err, returns.ErrorNoReturns{})
+	})
+}
+
+func TestList_BackfillWithRegression(t *testing.T) {
+	factorA := returns.List{
+		rtn(t, "2021-01-11", 0.01),
+		rtn(t, "2021-01-08", -0.02),
+		rtn(t, "2021-01-07", 0.03),
+		rtn(t, "2021-01-06", 0.00),
+		rtn(t, "2021-01-05", 0.015),
+		rtn(t, "2021-01-04", 0.02),
+	}
+	factorB := returns.List{
+		rtn(t, "2021-01-11", 0.00),
+		rtn(t, "2021-01-08", 0.01),
+		rtn(t, "2021-01-07", -0.01),
+		rtn(t, "2021-01-06", 0.02),
+		rtn(t, "2021-01-05", 0.005),
+		rtn(t, "2021-01-04", -0.01),
+	}
+	factors := returns.NewTable([]returns.List{factorA, factorB})
+	var asset returns.List
+	for i, r := range factorA[:4] {
+		asset = append(asset, rtn(t, r.Time.Format("2006-01-02"), 0.001+1.5*r.Value-0.5*factorB[i].Value))
+	}
+
+	result, splice, err := asset.BackfillWithRegression(factors, false)
+	require.NoError(t, err)
+	require.NotNil(t, splice.Regression)
+	assert.InDeltaSlice(t, []float64{1.5, -0.5}, splice.Regression.Coefficients, 1e-9)
+	assert.Equal(t, fixtures.T(t, "2021-01-06"), splice.Time)
+	assert.Equal(t, 2, splice.Backfilled)
+	require.Len(t, result, 6)
+	assert.InDelta(t, 0.001+1.5*0.015-0.5*0.005, result[4].Value, 1e-9)
+	assert.InDelta(t, 0.001+1.5*0.02-0.5*-0.01, result[5].Value, 1e-9)
+}
@@ -0,0 +1,212 @@
+package returns
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorCSVParse is returned by ReadCSV when a cell can not be parsed.
+// Line is the one-indexed line number and Column is the one-indexed field number (not the character offset).
+type ErrorCSVParse struct {
+	Line, Column int
+	Err          error
+}
+
+func (err ErrorCSVParse) Error() string {
+	return fmt.Sprintf("line %d column %d: %s", err.Line, err.Column, err.Err)
+}
+
+func (err ErrorCSVParse) Unwrap() error { return err.Err }
+
+// csvDateLayouts are tried in order when parsing the date column.
+var csvDateLayouts = []string{
+	time.DateOnly,
+	time.RFC3339,
+	"2006/01/02",
+	"01/02/2006",
+	"1/2/2006",
+	"20060102",
+	"02-Jan-2006",
+	"Jan 2, 2006",
+}
+
+// ReadCSV reads a table in the layout written by Table.WriteCSV and returns it with the column names.
+// The first column must contain dates; the header of the first column is ignored.
+// Rows may be in ascending or descending order. Dates may use any of these layouts:
+// 2006-01-02, RFC 3339, 2006/01/02, 01/02/2006 (month first), 20060102, 02-Jan-2006, or Jan 2, 2006.
+// Values may be percentage strings ("1.5%" is read as 0.015).
+// Rows with a missing cell (empty, "NA", "N/A", "null", or "-") are skipped
+// so the resulting table only has the rows where every column has a value.
+func ReadCSV(r io.Reader) (Table, []string, error) {
+	return readCSV(r, "", func(header []string, dateIndex int) ([]int, error) {
+		indexes := make([]int, 0, len(header)-1)
+		for j := range header {
+			if j != dateIndex {
+				indexes = append(indexes, j)
+			}
+		}
+		return indexes, nil
+	})
+}
+
+// ReadCSVColumn reads a single list of values from a CSV file with a header row.
+// Dates are read from the column with the header dateColumn and values from the column with the header valueColumn.
+// When dateColumn is empty the first column is used; when valueColumn is empty the first column that is not
+// the date column is used. Other columns are not parsed. Dates and values are parsed as in ReadCSV and
+// rows with a missing value are skipped.
+func ReadCSVColumn(r io.Reader, dateColumn, valueColumn string) (List, error) {
+	table, _, err := readCSV(r, dateColumn, func(header []string, dateIndex int) ([]int, error) {
+		if valueColumn == "" {
+			if dateIndex == 0 {
+				return []int{1}, nil
+			}
+			return []int{0}, nil
+		}
+		index := slices.IndexFunc(header, func(name string) bool { return strings.TrimSpace(name) == valueColumn })
+		if index < 0 {
+			return nil, fmt.Errorf("value column %q not found", valueColumn)
+		}
+		return []int{index}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return table.List(0), nil
+}
+
+// readCSV reads the dates in dateColumn (or the first column when dateColumn is empty)
+// and the values in the columns returned by valueColumns.
+func readCSV(r io.Reader, dateColumn string, valueColumns func(header []string, dateIndex int) ([]int, error)) (Table, []string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return Table{}, nil, errors.New("missing CSV header")
+		}
+		return Table{}, nil, err
+	}
+	if len(header) < 2 {
+		return Table{}, nil, errors.New("expected CSV header to have a date column and at least one value column")
+	}
+	dateIndex := 0
+	if dateColumn != "" {
+		dateIndex = slices.IndexFunc(header, func(name string) bool { return strings.TrimSpace(name) == dateColumn })
+		if dateIndex < 0 {
+			return Table{}, nil, fmt.Errorf("date column %q not found", dateColumn)
+		}
+	}
+	indexes, err := valueColumns(header, dateIndex)
+	if err != nil {
+		return Table{}, nil, err
+	}
+	columnNames := make([]string, len(indexes))
+	for k, j := range indexes {
+		columnNames[k] = header[j]
+	}
+
+	type row struct {
+		time   time.Time
+		values []float64
+	}
+	var (
+		rows []row
+		seen = make(map[time.Time]int)
+	)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Table{}, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) > len(header) {
+			return Table{}, nil, ErrorCSVParse{Line: line, Column: len(header) + 1, Err: fmt.Errorf("expected %d columns but got %d", len(header), len(record))}
+		}
+		if dateIndex >= len(record) {
+			return Table{}, nil, ErrorCSVParse{Line: line, Column: dateIndex + 1, Err: errors.New("missing date")}
+		}
+		tm, err := parseCSVDate(record[dateIndex])
+		if err != nil {
+			return Table{}, nil, ErrorCSVParse{Line: line, Column: dateIndex + 1, Err: err}
+		}
+		if previous, ok := seen[tm]; ok {
+			return Table{}, nil, ErrorCSVParse{Line: line, Column: dateIndex + 1, Err: fmt.Errorf("date %s is repeated from line %d", tm.Format(time.DateOnly), previous)}
+		}
+		seen[tm] = line
+
+		values := make([]float64, len(indexes))
+		complete := true
+		for k, j := range indexes {
+			if j >= len(record) {
+				complete = false
+				continue
+			}
+			value, ok, err := parseCSVValue(record[j])
+			if err != nil {
+				return Table{}, nil, ErrorCSVParse{Line: line, Column: j + 1, Err: err}
+			}
+			complete = complete && ok
+			values[k] = value
+		}
+		if complete {
+			rows = append(rows, row{time: tm, values: values})
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b row) int { return b.time.Compare(a.time) })
+	times := make([]time.Time, len(rows))
+	columns := make([][]float64, len(columnNames))
+	for j := range columns {
+		columns[j] = make([]float64, len(rows))
+	}
+	for i, r := range rows {
+		times[i] = r.time
+		for j, value := range r.values {
+			columns[j][i] = value
+		}
+	}
+	return NewTableFromValues(times, columns), columnNames, nil
+}
+
+func parseCSVDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range csvDateLayouts {
+		if tm, err := time.Parse(layout, s); err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse date %q", s)
+}
+
+func parseCSVValue(s string) (float64, bool, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", "na", "n/a", "null", "-":
+		return 0, false, nil
+	}
+	divisor := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+		divisor = 100
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false, fmt.Errorf("failed to parse value %q", s)
+	}
+	return value / divisor, true, nil
+}
@@ -0,0 +1,88 @@
+package returns_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/internal/fixtures"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestReadCSV(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		table := returns.NewTable([]returns.List{
+			{rtn(t, fixtures.Day2, 0.1), rtn(t, fixtures.Day1, -0.2), rtn(t, fixtures.Day0, 0.3)},
+			{rtn(t, fixtures.Day2, 0.01), rtn(t, fixtures.Day1, 0.02), rtn(t, fixtures.Day0, -0.03)},
+		})
+		var buf bytes.Buffer
+		require.NoError(t, table.WriteCSV(&buf, []string{"apple", "banana"}))
+
+		result, names, err := returns.ReadCSV(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"apple", "banana"}, names)
+		assert.True(t, table.Equal(result))
+	})
+
+	t.Run("ascending rows with other date layouts, percentages, and missing cells", func(t *testing.T) {
+		result, names, err := returns.ReadCSV(strings.NewReader(strings.Join([]string{
+			"Day,A,B",
+			"01/04/2021,1%,0.5",
+			"2021/01/05,NA,0.25",
+			"2021-01-06, -2.5% ,",
+			"2021-01-07,0.03,0.04",
+		}, "\n")))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A", "B"}, names)
+		require.Equal(t, 2, result.NumberOfRows())
+		assert.Equal(t, fixtures.T(t, "2021-01-07"), result.LastTime())
+		assert.Equal(t, fixtures.T(t, "2021-01-04"), result.FirstTime())
+		assert.Equal(t, []float64{0.03, 0.01}, result.List(0).Values())
+		assert.Equal(t, []float64{0.04, 0.5}, result.List(1).Values())
+	})
+
+	for _, tt := range []struct {
+		Name           string
+		CSV            string
+		Line, Column   int
+		ErrorSubstring string
+	}{
+		{Name: "bad date", CSV: "Date,A\n2021-01-04,0.1\nbanana,0.2\n", Line: 3, Column: 1, ErrorSubstring: `failed to parse date "banana"`},
+		{Name: "bad value", CSV: "Date,A,B\n2021-01-04,0.1,0.2\n2021-01-05,0.1,x\n", Line: 3, Column: 3, ErrorSubstring: `failed to parse value "x"`},
+		{Name: "repeated date", CSV: "Date,A\n2021-01-04,0.1\n2021-01-04,0.2\n", Line: 3, Column: 1, ErrorSubstring: "repeated from line 2"},
+		{Name: "too many cells", CSV: "Date,A\n2021-01-04,0.1,0.2\n", Line: 2, Column: 3, ErrorSubstring: "expected 2 columns but got 3"},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, _, err := returns.ReadCSV(strings.NewReader(tt.CSV))
+			var parseErr returns.ErrorCSVParse
+			require.True(t, errors.As(err, &parseErr), "got %v", err)
+			assert.Equal(t, tt.Line, parseErr.Line)
+			assert.Equal(t, tt.Column, parseErr.Column)
+			assert.ErrorContains(t, err, tt.ErrorSubstring)
+		})
+	}
+
+	t.Run("missing header", func(t *testing.T) {
+		_, _, err := returns.ReadCSV(strings.NewReader(""))
+		assert.ErrorContains(t, err, "header")
+	})
+
+	t.Run("single column", func(t *testing.T) {
+		list, err := returns.ReadCSVColumn(strings.NewReader("Symbol,Day,Volume,Close\nA,2021-01-04,,100\nA,2021-01-05,10,110\n"), "Day", "Close")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{110, 100}, list.Values(), "other columns are not parsed")
+
+		list, err = returns.ReadCSVColumn(strings.NewReader("Date,A,B\n2021-01-04,0.1,x\n"), "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.1}, list.Values())
+
+		_, err = returns.ReadCSVColumn(strings.NewReader("Date,A\n2021-01-04,0.1\n"), "Day", "")
+		assert.ErrorContains(t, err, `date column "Day" not found`)
+		_, err = returns.ReadCSVColumn(strings.NewReader("Date,A\n2021-01-04,0.1\n"), "", "B")
+		assert.ErrorContains(t, err, `value column "B" not found`)
+	})
+}
@@ -0,0 +1,74 @@
+package returns
+
+import (
+	"slices"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+// Drawdown is a decline in the growth of a list from a peak and the recovery back to that peak.
+type Drawdown struct {
+	// Peak is the time of the return that set the peak. It is zero when the drawdown starts with the oldest return.
+	Peak time.Time `json:"peak" bson:"peak"`
+	// Trough is the time of the lowest growth in the drawdown.
+	Trough time.Time `json:"trough" bson:"trough"`
+	// Recovery is the time growth got back to the peak. It is zero when the drawdown is ongoing.
+	Recovery time.Time `json:"recovery" bson:"recovery"`
+	Ongoing  bool      `json:"ongoing"  bson:"ongoing"`
+	// Depth is the decline from the peak to the trough as a positive fraction of the peak.
+	Depth float64 `json:"depth" bson:"depth"`
+	// Length is the number of returns after the peak up to the recovery or the newest return when ongoing.
+	Length int `json:"length" bson:"length"`
+	// TimeToRecover is the number of returns after the trough up to the recovery. It is zero when ongoing.
+	TimeToRecover int `json:"timeToRecover" bson:"timeToRecover"`
+}
+
+// Underwater returns the decline of the growth of list from its running peak for each return.
+func (list List) Underwater() List {
+	list = sortedClone(list)
+	for i, value := range calculate.Underwater(list.Values()) {
+		list[i].Value = value
+	}
+	return list
+}
+
+// Drawdowns returns every drawdown in list sorted newest first.
+func (list List) Drawdowns() []Drawdown {
+	list = sortedClone(list)
+	episodes := calculate.Drawdowns(list.Values())
+	result := make([]Drawdown, len(episodes))
+	for i, episode := range episodes {
+		drawdown := Drawdown{
+			Trough:        list[episode.Trough].Time,
+			Ongoing:       episode.Ongoing(),
+			Depth:         episode.Depth,
+			Length:        episode.Length(),
+			TimeToRecover: episode.TimeToRecover(),
+		}
+		if episode.Peak < len(list) {
+			drawdown.Peak = list[episode.Peak].Time
+		}
+		if !drawdown.Ongoing {
+			drawdown.Recovery = list[episode.Recovery].Time
+		}
+		result[i] = drawdown
+	}
+	return result
+}
+
+// LargestDrawdowns returns up to n drawdowns sorted by depth, deepest first. It returns none when n is not positive.
+func (list List) LargestDrawdowns(n int) []Drawdown {
+	drawdowns := list.Drawdowns()
+	slices.SortStableFunc(drawdowns, func(a, b Drawdown) int {
+		switch {
+		case a.Depth > b.Depth:
+			return -1
+		case a.Depth < b.Depth:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return drawdowns[:max(min(n, len(drawdowns)), 0)]
+}
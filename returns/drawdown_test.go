@@ -0,0 +1,58 @@
+package returns_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/internal/fixtures"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestList_Drawdowns(t *testing.T) {
+	list := returns.List{
+		rtn(t, "2021-01-11", -0.5),
+		rtn(t, "2021-01-08", 0.25),
+		rtn(t, "2021-01-07", -0.1),
+		rtn(t, "2021-01-06", -0.1),
+		rtn(t, "2021-01-05", 0.1),
+		rtn(t, "2021-01-04", -0.05),
+	}
+
+	t.Run("underwater", func(t *testing.T) {
+		underwater := list.Underwater()
+		assert.Equal(t, list.Times(), underwater.Times())
+		assert.InDeltaSlice(t, []float64{-0.5, 0, -0.19, -0.1, 0, -0.05}, underwater.Values(), 1e-9)
+	})
+
+	t.Run("episodes", func(t *testing.T) {
+		drawdowns := list.Drawdowns()
+		require.Len(t, drawdowns, 3)
+
+		assert.Equal(t, fixtures.T(t, "2021-01-08"), drawdowns[0].Peak)
+		assert.Equal(t, fixtures.T(t, "2021-01-11"), drawdowns[0].Trough)
+		assert.True(t, drawdowns[0].Ongoing)
+		assert.True(t, drawdowns[0].Recovery.IsZero())
+
+		assert.Equal(t, fixtures.T(t, "2021-01-05"), drawdowns[1].Peak)
+		assert.Equal(t, fixtures.T(t, "2021-01-07"), drawdowns[1].Trough)
+		assert.Equal(t, fixtures.T(t, "2021-01-08"), drawdowns[1].Recovery)
+		assert.InDelta(t, 0.19, drawdowns[1].Depth, 1e-9)
+		assert.Equal(t, 3, drawdowns[1].Length)
+		assert.Equal(t, 1, drawdowns[1].TimeToRecover)
+
+		assert.Equal(t, time.Time{}, drawdowns[2].Peak, "the peak is the starting value")
+		assert.Equal(t, fixtures.T(t, "2021-01-05"), drawdowns[2].Recovery)
+	})
+
+	t.Run("largest", func(t *testing.T) {
+		largest := list.LargestDrawdowns(2)
+		require.Len(t, largest, 2)
+		assert.InDelta(t, 0.5, largest[0].Depth, 1e-9)
+		assert.InDelta(t, 0.19, largest[1].Depth, 1e-9)
+		assert.Len(t, list.LargestDrawdowns(10), 3)
+		assert.Empty(t, list.LargestDrawdowns(-1))
+	})
+}
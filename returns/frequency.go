@@ -0,0 +1,183 @@
+package returns
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+// Frequency is the calendar period covered by each return.
+type Frequency string
+
+const (
+	Daily     Frequency = "Daily"
+	Weekly    Frequency = "Weekly"
+	Monthly   Frequency = "Monthly"
+	Quarterly Frequency = "Quarterly"
+	Annually  Frequency = "Annually"
+)
+
+func Frequencies() []Frequency {
+	return []Frequency{Daily, Weekly, Monthly, Quarterly, Annually}
+}
+
+func (frequency Frequency) Validate() error {
+	if !slices.Contains(Frequencies(), frequency) {
+		return fmt.Errorf("unknown frequency %q", frequency)
+	}
+	return nil
+}
+
+// PeriodsPerYear is used to annualize statistics. Daily uses calculate.PeriodsPerYear (trading days).
+func (frequency Frequency) PeriodsPerYear() float64 {
+	switch frequency {
+	case Weekly:
+		return 52
+	case Monthly:
+		return 12
+	case Quarterly:
+		return 4
+	case Annually:
+		return 1
+	default:
+		return calculate.PeriodsPerYear
+	}
+}
+
+// PeriodEnd returns the last calendar day of the period containing t.
+// Weeks end on Friday; Saturday and Sunday are part of the following week.
+func (frequency Frequency) PeriodEnd(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch frequency {
+	case Weekly:
+		return time.Date(y, m, d+(int(time.Friday)-int(t.Weekday())+7)%7, 0, 0, 0, 0, t.Location())
+	case Monthly:
+		return time.Date(y, m+1, 0, 0, 0, 0, 0, t.Location())
+	case Quarterly:
+		return time.Date(y, m+(3-(m-1)%3), 0, 0, 0, 0, 0, t.Location())
+	case Annually:
+		return time.Date(y, time.December, 31, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// frequencyGapLimits are the largest median number of calendar days between times for each frequency.
+var frequencyGapLimits = [...]struct {
+	days      float64
+	frequency Frequency
+}{
+	{days: 4, frequency: Daily},
+	{days: 10, frequency: Weekly},
+	{days: 45, frequency: Monthly},
+	{days: 120, frequency: Quarterly},
+	{days: math.Inf(1), frequency: Annually},
+}
+
+// DetectFrequency uses the median number of calendar days between times to guess the frequency.
+// It returns Daily when there are fewer than two times.
+func DetectFrequency(times []time.Time) Frequency {
+	return detectFrequency(len(times), func(i int) time.Time { return times[i] })
+}
+
+// detectFrequency counts the gaps in each frequency bucket so it does not allocate or sort
+// unless the two middle gaps are in different buckets.
+func detectFrequency(n int, timeAt func(i int) time.Time) Frequency {
+	if n < 2 {
+		return Daily
+	}
+	gap := func(i int) float64 {
+		return math.Abs(timeAt(i-1).Sub(timeAt(i)).Hours() / 24)
+	}
+	var counts [len(frequencyGapLimits)]int
+	for i := 1; i < n; i++ {
+		counts[gapBucket(gap(i))]++
+	}
+	numberOfGaps := n - 1
+	lower, upper := bucketOfRank(counts[:], (numberOfGaps-1)/2), bucketOfRank(counts[:], numberOfGaps/2)
+	if lower == upper {
+		return frequencyGapLimits[lower].frequency
+	}
+	// the median is the mean of two gaps in different buckets
+	gaps := make([]float64, 0, numberOfGaps)
+	for i := 1; i < n; i++ {
+		gaps = append(gaps, gap(i))
+	}
+	slices.Sort(gaps)
+	return frequencyGapLimits[gapBucket((gaps[(numberOfGaps-1)/2]+gaps[numberOfGaps/2])/2)].frequency
+}
+
+func gapBucket(days float64) int {
+	for i, limit := range frequencyGapLimits {
+		if days <= limit.days {
+			return i
+		}
+	}
+	return len(frequencyGapLimits) - 1
+}
+
+// bucketOfRank returns the bucket of the gap at index rank if the gaps were sorted.
+func bucketOfRank(counts []int, rank int) int {
+	for i, count := range counts {
+		if rank < count {
+			return i
+		}
+		rank -= count
+	}
+	return len(counts) - 1
+}
+
+// PeriodEndConvention sets the time of resampled returns.
+type PeriodEndConvention int
+
+const (
+	// LastObservation uses the time of the most recent return in each period.
+	LastObservation PeriodEndConvention = iota
+	// CalendarPeriodEnd uses the last calendar day of each period (see Frequency.PeriodEnd).
+	CalendarPeriodEnd
+)
+
+func (list List) Frequency() Frequency {
+	return detectFrequency(len(list), func(i int) time.Time { return list[i].Time })
+}
+
+func (table Table) Frequency() Frequency { return DetectFrequency(table.times) }
+
+// Resample geometrically compounds the returns in each calendar period into one return.
+// The first and last periods may be partial.
+func (list List) Resample(frequency Frequency, convention PeriodEndConvention) List {
+	table := NewTableFromValues(list.Times(), [][]float64{list.Values()}).Resample(frequency, convention)
+	return table.List(0)
+}
+
+// Resample geometrically compounds the returns in each calendar period into one return per column.
+// The first and last periods may be partial.
+func (table Table) Resample(frequency Frequency, convention PeriodEndConvention) Table {
+	var (
+		times  []time.Time
+		values = make([][]float64, len(table.values))
+		period time.Time
+	)
+	for i, tm := range table.times {
+		end := frequency.PeriodEnd(tm)
+		if len(times) == 0 || !end.Equal(period) {
+			period = end
+			if convention == CalendarPeriodEnd {
+				times = append(times, end)
+			} else {
+				times = append(times, tm)
+			}
+			for c := range values {
+				values[c] = append(values[c], 0)
+			}
+		}
+		last := len(times) - 1
+		for c := range values {
+			values[c][last] = (1+values[c][last])*(1+table.values[c][i]) - 1
+		}
+	}
+	return NewTableFromValues(times, values)
+}
@@ -0,0 +1,137 @@
+package returns_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/internal/fixtures"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestFrequency_PeriodEnd(t *testing.T) {
+	for _, tt := range []struct {
+		Frequency returns.Frequency
+		Day, End  string
+	}{
+		{returns.Daily, "2021-02-10", "2021-02-10"},
+		{returns.Weekly, "2021-02-10", "2021-02-12"},
+		{returns.Weekly, "2021-02-12", "2021-02-12"},
+		{returns.Weekly, "2021-02-13", "2021-02-19"},
+		{returns.Monthly, "2021-02-10", "2021-02-28"},
+		{returns.Monthly, "2020-12-01", "2020-12-31"},
+		{returns.Quarterly, "2021-01-05", "2021-03-31"},
+		{returns.Quarterly, "2021-06-30", "2021-06-30"},
+		{returns.Quarterly, "2021-11-15", "2021-12-31"},
+		{returns.Annually, "2021-02-10", "2021-12-31"},
+	} {
+		t.Run(string(tt.Frequency)+" "+tt.Day, func(t *testing.T) {
+			assert.Equal(t, fixtures.T(t, tt.End), tt.Frequency.PeriodEnd(fixtures.T(t, tt.Day)))
+		})
+	}
+}
+
+func TestDetectFrequency(t *testing.T) {
+	series := func(start string, step func(time.Time) time.Time, n int) []time.Time {
+		tm := fixtures.T(t, start)
+		var times []time.Time
+		for i := 0; i < n; i++ {
+			times = append([]time.Time{tm}, times...)
+			tm = step(tm)
+		}
+		return times
+	}
+	weekdays := func(tm time.Time) time.Time {
+		tm = tm.AddDate(0, 0, 1)
+		for tm.Weekday() == time.Saturday || tm.Weekday() == time.Sunday {
+			tm = tm.AddDate(0, 0, 1)
+		}
+		return tm
+	}
+
+	assert.Equal(t, returns.Daily, returns.DetectFrequency(nil))
+	assert.Equal(t, returns.Daily, returns.DetectFrequency(series("2021-01-04", weekdays, 30)))
+	assert.Equal(t, returns.Weekly, returns.DetectFrequency(series("2021-01-08", func(tm time.Time) time.Time { return tm.AddDate(0, 0, 7) }, 10)))
+	assert.Equal(t, returns.Monthly, returns.DetectFrequency(series("2021-01-31", func(tm time.Time) time.Time { return returns.Monthly.PeriodEnd(tm.AddDate(0, 0, 1)) }, 12)))
+	assert.Equal(t, returns.Quarterly, returns.DetectFrequency(series("2021-03-31", func(tm time.Time) time.Time { return returns.Quarterly.PeriodEnd(tm.AddDate(0, 0, 1)) }, 8)))
+	assert.Equal(t, returns.Annually, returns.DetectFrequency(series("2010-12-31", func(tm time.Time) time.Time { return tm.AddDate(1, 0, 0) }, 5)))
+
+	t.Run("holiday week", func(t *testing.T) {
+		// Christmas 2020 was on a Friday and New Year's Day 2021 was on the next Friday
+		times := []time.Time{
+			fixtures.T(t, "2021-01-04"),
+			fixtures.T(t, "2020-12-31"),
+			fixtures.T(t, "2020-12-30"),
+			fixtures.T(t, "2020-12-29"),
+			fixtures.T(t, "2020-12-28"),
+			fixtures.T(t, "2020-12-24"),
+		}
+		assert.Equal(t, returns.Daily, returns.DetectFrequency(times))
+		assert.Equal(t, returns.Daily, returns.DetectFrequency(times[4:]), "a long weekend is daily")
+		assert.Equal(t, returns.Daily, returns.DetectFrequency([]time.Time{times[0], times[1], times[4], times[5]}))
+
+		list := make(returns.List, len(times))
+		for i, tm := range times {
+			list[i] = returns.New(tm, 0.01*float64(i%2))
+		}
+		assert.Equal(t, returns.Daily, list.Frequency())
+		table := returns.NewTable([]returns.List{list})
+		assert.InDelta(t, list.Risk()*math.Sqrt(calculate.PeriodsPerYear), table.AnnualizedRisks()[0], 1e-12)
+	})
+
+	t.Run("middle gaps have different frequencies", func(t *testing.T) {
+		day := fixtures.T(t, "2021-01-04")
+		assert.Equal(t, returns.Daily, returns.DetectFrequency([]time.Time{day.AddDate(0, 0, 8), day.AddDate(0, 0, 1), day}), "the median gap is 4 days")
+		assert.Equal(t, returns.Weekly, returns.DetectFrequency([]time.Time{day.AddDate(0, 0, 12), day.AddDate(0, 0, 1), day}), "the median gap is 6 days")
+	})
+}
+
+func TestList_Resample(t *testing.T) {
+	list := returns.List{
+		rtn(t, "2021-02-02", 0.1),
+		rtn(t, "2021-02-01", -0.1),
+		rtn(t, "2021-01-29", 0.2),
+		rtn(t, "2021-01-28", 0.1),
+	}
+
+	t.Run("last observation", func(t *testing.T) {
+		monthly := list.Resample(returns.Monthly, returns.LastObservation)
+		require.Len(t, monthly, 2)
+		assert.Equal(t, fixtures.T(t, "2021-02-02"), monthly[0].Time)
+		assert.InDelta(t, 1.1*0.9-1, monthly[0].Value, 1e-12)
+		assert.Equal(t, fixtures.T(t, "2021-01-29"), monthly[1].Time)
+		assert.InDelta(t, 1.2*1.1-1, monthly[1].Value, 1e-12)
+	})
+
+	t.Run("calendar period end", func(t *testing.T) {
+		monthly := list.Resample(returns.Monthly, returns.CalendarPeriodEnd)
+		assert.Equal(t, []time.Time{fixtures.T(t, "2021-02-28"), fixtures.T(t, "2021-01-31")}, monthly.Times())
+	})
+
+	t.Run("annualization uses the detected frequency", func(t *testing.T) {
+		monthly := returns.List{
+			rtn(t, "2021-04-30", 0.02),
+			rtn(t, "2021-03-31", -0.01),
+			rtn(t, "2021-02-28", 0.03),
+			rtn(t, "2021-01-31", 0.01),
+		}
+		assert.InDelta(t, monthly.Risk()*math.Sqrt(12), monthly.AnnualizedRisk(), 1e-12)
+		assert.InDelta(t, calculate.AnnualizedArithmeticReturn(monthly.Values(), 12), monthly.AnnualizedArithmeticReturn(), 1e-12)
+	})
+}
+
+func TestTable_Resample(t *testing.T) {
+	table := returns.NewTable([]returns.List{
+		{rtn(t, "2021-01-12", 0.1), rtn(t, "2021-01-11", 0.1), rtn(t, "2021-01-08", 0.1)},
+		{rtn(t, "2021-01-12", -0.1), rtn(t, "2021-01-11", 0), rtn(t, "2021-01-08", 0.2)},
+	})
+	weekly := table.Resample(returns.Weekly, returns.CalendarPeriodEnd)
+	assert.Equal(t, []time.Time{fixtures.T(t, "2021-01-15"), fixtures.T(t, "2021-01-08")}, weekly.Times())
+	assert.InDeltaSlice(t, []float64{1.1*1.1 - 1, 0.1}, weekly.List(0).Values(), 1e-12)
+	assert.InDeltaSlice(t, []float64{-0.1, 0.2}, weekly.List(1).Values(), 1e-12)
+	assert.Equal(t, returns.Daily, table.Frequency())
+}
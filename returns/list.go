@@ -19,7 +19,7 @@ func (list List) Swap(i, j int)      { list[i], list[j] = list[j], list[i] }
 
 func (list List) AddSpread(annualizedSpread float64) List {
 	result := slices.Clone(list)
-	s := math.Pow(1.0+annualizedSpread, 1.0/calculate.PeriodsPerYear) - 1
+	s := math.Pow(1.0+annualizedSpread, 1.0/list.Frequency().PeriodsPerYear()) - 1
 	for i := range result {
 		result[i].Value = result[i].Value + s
 	}
@@ -109,16 +109,16 @@ func (list List) Risk() float64 {
 
 // AnnualizedRisk must receive at least 2 returns otherwise it returns 0
 func (list List) AnnualizedRisk() float64 {
-	return calculate.AnnualizeRisk(list.Risk(), calculate.PeriodsPerYear)
+	return calculate.AnnualizeRisk(list.Risk(), list.Frequency().PeriodsPerYear())
 }
 
 // AnnualizedTimeWeightedReturn must receive at least 2 returns otherwise it returns 0
 func (list List) AnnualizedTimeWeightedReturn() float64 {
-	return calculate.AnnualizedTimeWeightedReturn(list.Values(), calculate.PeriodsPerYear)
+	return calculate.AnnualizedTimeWeightedReturn(list.Values(), list.Frequency().PeriodsPerYear())
 }
 
 func (list List) AnnualizedArithmeticReturn() float64 {
-	return calculate.AnnualizedArithmeticReturn(list.Values(), calculate.PeriodsPerYear)
+	return calculate.AnnualizedArithmeticReturn(list.Values(), list.Frequency().PeriodsPerYear())
 }
 
 func compareTimes(x, y time.Time) int {
@@ -0,0 +1,161 @@
+package returns
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+// Quote is the closing price of an asset on Time.
+// Dividend is the cash distributed per share going ex-dividend on Time.
+// Split is the number of shares held after a split on Time for each share held before (2 for a 2-for-1 split).
+// A zero Split means there was no split.
+type Quote struct {
+	Time     time.Time `json:"time"               bson:"time"`
+	Close    float64   `json:"close"              bson:"close"`
+	Dividend float64   `json:"dividend,omitempty" bson:"dividend,omitempty"`
+	Split    float64   `json:"split,omitempty"    bson:"split,omitempty"`
+}
+
+func (quote Quote) splitRatio() float64 {
+	if quote.Split == 0 {
+		return 1
+	}
+	return quote.Split
+}
+
+// Quotes is a price series. Like List, it is sorted newest first.
+type Quotes []Quote
+
+func (quotes Quotes) Sort()              { sort.Sort(quotes) }
+func (quotes Quotes) Less(i, j int) bool { return quotes[i].Time.After(quotes[j].Time) }
+func (quotes Quotes) Len() int           { return len(quotes) }
+func (quotes Quotes) Swap(i, j int)      { quotes[i], quotes[j] = quotes[j], quotes[i] }
+
+func (quotes Quotes) Times() []time.Time {
+	result := make([]time.Time, len(quotes))
+	for i := range quotes {
+		result[i] = quotes[i].Time
+	}
+	return result
+}
+
+func (quotes Quotes) Closes() []float64 {
+	result := make([]float64, len(quotes))
+	for i := range quotes {
+		result[i] = quotes[i].Close
+	}
+	return result
+}
+
+// Between returns a slice of quotes. See List.Between.
+func (quotes Quotes) Between(t1, t0 time.Time) Quotes {
+	tmFn := func(q Quote) time.Time { return q.Time }
+	last, first := lowAndHighIndexesWithinTimes(quotes, t1, t0, tmFn)
+	return quotes[last:first:first]
+}
+
+// PriceReturns returns the split adjusted price returns. Dividends are ignored.
+// The oldest quote does not have a return.
+func (quotes Quotes) PriceReturns() List {
+	adjusted := quotes.Closes()
+	factor := 1.0
+	for i := range quotes {
+		adjusted[i] /= factor
+		factor *= quotes[i].splitRatio()
+	}
+	return newListFromValues(quotes.Times(), calculate.HoldingPeriodReturns(adjusted))
+}
+
+// TotalReturns returns the split adjusted returns with dividends reinvested on the ex-dividend date.
+// The oldest quote does not have a return.
+func (quotes Quotes) TotalReturns() List {
+	if len(quotes) < 2 {
+		return nil
+	}
+	result := make(List, len(quotes)-1)
+	for i := range result {
+		q := quotes[i]
+		result[i] = New(q.Time, (q.Close+q.Dividend)*q.splitRatio()/quotes[i+1].Close-1)
+	}
+	return result
+}
+
+// TotalReturnIndex returns the growth of initial invested at the oldest close
+// with dividends reinvested. The index has a value for every quote.
+func (quotes Quotes) TotalReturnIndex(initial float64) Quotes {
+	list := quotes.TotalReturns()
+	index := make(Quotes, len(quotes))
+	value := initial
+	for i := len(quotes) - 1; i >= 0; i-- {
+		if i < len(list) {
+			value *= 1 + list[i].Value
+		}
+		index[i] = Quote{Time: quotes[i].Time, Close: value}
+	}
+	return index
+}
+
+// NewTableFromQuotes returns a table with a column of total returns for each price series.
+// The series are aligned to the times present in every series; returns over the skipped
+// times (and any dividends or splits on those times) are compounded into the next aligned time.
+func NewTableFromQuotes(list []Quotes) Table {
+	if len(list) == 0 {
+		return Table{}
+	}
+	times := slices.Clone(list[0].Times())
+	for _, quotes := range list[1:] {
+		times = slices.DeleteFunc(times, func(tm time.Time) bool {
+			_, found := slices.BinarySearchFunc(quotes, tm, func(q Quote, t time.Time) int {
+				return t.Compare(q.Time)
+			})
+			return !found
+		})
+	}
+	if len(times) < 2 {
+		return NewTableFromValues(nil, make([][]float64, len(list)))
+	}
+
+	values := make([][]float64, len(list))
+	for c, quotes := range list {
+		index := quotes.TotalReturnIndex(1)
+		aligned := make([]float64, 0, len(times))
+		for _, q := range index {
+			if _, found := slices.BinarySearchFunc(times, q.Time, func(et, t time.Time) int {
+				return t.Compare(et)
+			}); found {
+				aligned = append(aligned, q.Close)
+			}
+		}
+		values[c] = calculate.HoldingPeriodReturns(aligned)
+	}
+	return NewTableFromValues(times[:len(times)-1], values)
+}
+
+// GrowthIndexes is the inverse of NewTableFromQuotes. It returns the growth of initial
+// for each column where the oldest value is initial at the time before the first row.
+// The time of the initial value is not known so it is the zero time.Time.
+func (table Table) GrowthIndexes(initial float64) []Quotes {
+	result := make([]Quotes, len(table.values))
+	for c, values := range table.values {
+		index := make(Quotes, len(values)+1)
+		value := initial
+		index[len(values)] = Quote{Close: value}
+		for i := len(values) - 1; i >= 0; i-- {
+			value *= 1 + values[i]
+			index[i] = Quote{Time: table.times[i], Close: value}
+		}
+		result[c] = index
+	}
+	return result
+}
+
+func newListFromValues(times []time.Time, values []float64) List {
+	result := make(List, len(values))
+	for i := range values {
+		result[i] = New(times[i], values[i])
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package returns_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio/internal/fixtures"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestQuotes(t *testing.T) {
+	quotes := returns.Quotes{
+		{Time: fixtures.T(t, "2021-01-06"), Close: 55, Split: 2},
+		{Time: fixtures.T(t, "2021-01-05"), Close: 99, Dividend: 1},
+		{Time: fixtures.T(t, "2021-01-04"), Close: 100},
+	}
+
+	t.Run("price returns", func(t *testing.T) {
+		list := quotes.PriceReturns()
+		require.Len(t, list, 2)
+		assert.Equal(t, fixtures.T(t, "2021-01-06"), list[0].Time)
+		assert.InDelta(t, 110.0/99-1, list[0].Value, 1e-12)
+		assert.InDelta(t, -0.01, list[1].Value, 1e-12)
+	})
+
+	t.Run("total returns", func(t *testing.T) {
+		list := quotes.TotalReturns()
+		require.Len(t, list, 2)
+		assert.InDelta(t, 110.0/99-1, list[0].Value, 1e-12)
+		assert.InDelta(t, 0, list[1].Value, 1e-12)
+	})
+
+	t.Run("total return index", func(t *testing.T) {
+		index := quotes.TotalReturnIndex(1)
+		assert.InDeltaSlice(t, []float64{110.0 / 99, 1, 1}, index.Closes(), 1e-12)
+		assert.Equal(t, quotes.Times(), index.Times())
+	})
+
+	t.Run("between", func(t *testing.T) {
+		assert.Equal(t, quotes[:2], quotes.Between(fixtures.T(t, "2021-01-06"), fixtures.T(t, "2021-01-05")))
+	})
+}
+
+func TestNewTableFromQuotes(t *testing.T) {
+	a := returns.Quotes{
+		{Time: fixtures.T(t, "2021-01-07"), Close: 12},
+		{Time: fixtures.T(t, "2021-01-06"), Close: 11},
+		{Time: fixtures.T(t, "2021-01-05"), Close: 10, Dividend: 1},
+		{Time: fixtures.T(t, "2021-01-04"), Close: 10},
+	}
+	b := returns.Quotes{
+		{Time: fixtures.T(t, "2021-01-07"), Close: 21},
+		{Time: fixtures.T(t, "2021-01-05"), Close: 20},
+		{Time: fixtures.T(t, "2021-01-04"), Close: 25},
+	}
+
+	table := returns.NewTableFromQuotes([]returns.Quotes{a, b})
+	assert.Equal(t, []time.Time{fixtures.T(t, "2021-01-07"), fixtures.T(t, "2021-01-05")}, table.Times())
+	assert.InDeltaSlice(t, []float64{1.2 - 1, 0.1}, table.List(0).Values(), 1e-12, "the skipped day is compounded into the next aligned day")
+	assert.InDeltaSlice(t, []float64{0.05, -0.2}, table.List(1).Values(), 1e-12)
+
+	t.Run("growth indexes round trip", func(t *testing.T) {
+		indexes := table.GrowthIndexes(1)
+		require.Len(t, indexes, 2)
+		assert.InDeltaSlice(t, []float64{0.8 * 1.05, 0.8, 1}, indexes[1].Closes(), 1e-12)
+		assert.True(t, indexes[1][2].Time.IsZero())
+
+		result := returns.NewTableFromQuotes(indexes)
+		assert.Equal(t, table.Times(), result.Times())
+		for c := range table.ColumnValues() {
+			assert.InDeltaSlice(t, table.ColumnValues()[c], result.ColumnValues()[c], 1e-12)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package returns
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Window sets the oldest time in a rolling window ending on a given day.
+// backtestconfig.Window implements Window.
+type Window interface {
+	Sub(today time.Time) time.Time
+}
+
+// RollingRisk returns the annualized sample standard deviation of each column over each window.
+//
+// The Rolling methods calculate a row for each time with a full window, that is when the window
+// starts on or after the oldest time in the table. Statistics are annualized using the table Frequency.
+// Window sums are updated as rows enter and leave the window so each row is only visited twice.
+// Ratios are NaN or infinite when a denominator column does not vary within a window.
+func (table Table) RollingRisk(window Window) Table {
+	periodsPerYear := table.Frequency().PeriodsPerYear()
+	return table.roll(window, len(table.values), func(out []float64, moments *rollingMoments) {
+		for i := range out {
+			out[i] = math.Sqrt(math.Max(moments.covariance(i, i), 0) * periodsPerYear)
+		}
+	})
+}
+
+// RollingSharpeRatio returns the annualized arithmetic return in excess of the risk-free column
+// divided by the annualized risk of each column over each window.
+// Pass a negative riskFreeColumn to use a risk-free return of zero.
+func (table Table) RollingSharpeRatio(window Window, riskFreeColumn int) Table {
+	periodsPerYear := table.Frequency().PeriodsPerYear()
+	return table.roll(window, len(table.values), func(out []float64, moments *rollingMoments) {
+		riskFree := 0.0
+		if riskFreeColumn >= 0 {
+			riskFree = moments.mean(riskFreeColumn)
+		}
+		for i := range out {
+			out[i] = (moments.mean(i) - riskFree) * math.Sqrt(periodsPerYear) / math.Sqrt(moments.covariance(i, i))
+		}
+	})
+}
+
+// RollingBeta returns the beta of each column to the benchmark column over each window.
+func (table Table) RollingBeta(window Window, benchmarkColumn int) Table {
+	return table.roll(window, len(table.values), func(out []float64, moments *rollingMoments) {
+		variance := moments.covariance(benchmarkColumn, benchmarkColumn)
+		for i := range out {
+			out[i] = moments.covariance(i, benchmarkColumn) / variance
+		}
+	})
+}
+
+// RollingTrackingError returns the annualized standard deviation of the difference between each column
+// and the benchmark column over each window.
+func (table Table) RollingTrackingError(window Window, benchmarkColumn int) Table {
+	periodsPerYear := table.Frequency().PeriodsPerYear()
+	b := benchmarkColumn
+	return table.roll(window, len(table.values), func(out []float64, moments *rollingMoments) {
+		for i := range out {
+			variance := moments.covariance(i, i) + moments.covariance(b, b) - 2*moments.covariance(i, b)
+			out[i] = math.Sqrt(math.Max(variance, 0) * periodsPerYear)
+		}
+	})
+}
+
+// RollingCorrelation returns the correlation of each pair of columns over each window.
+// The result has a column for each pair in the order returned by ColumnPairs.
+func (table Table) RollingCorrelation(window Window) Table {
+	pairs := ColumnPairs(len(table.values))
+	return table.roll(window, len(pairs), func(out []float64, moments *rollingMoments) {
+		for k, pair := range pairs {
+			i, j := pair[0], pair[1]
+			out[k] = moments.covariance(i, j) / math.Sqrt(moments.covariance(i, i)*moments.covariance(j, j))
+		}
+	})
+}
+
+// ColumnPairs returns the pairs of column indexes (i, j) with i < j, ordered by i then j.
+func ColumnPairs(numberOfColumns int) [][2]int {
+	var pairs [][2]int
+	for i := 0; i < numberOfColumns; i++ {
+		for j := i + 1; j < numberOfColumns; j++ {
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+	return pairs
+}
+
+// roll walks the table from oldest to newest adding each row to the window sums and removing rows that
+// leave the window. It calls compute for each full window with at least two rows.
+func (table Table) roll(window Window, numberOfColumns int, compute func(out []float64, moments *rollingMoments)) Table {
+	var (
+		times   []time.Time
+		values  = make([][]float64, numberOfColumns)
+		moments = newRollingMoments(len(table.values))
+		first   = table.FirstTime()
+		oldest  = len(table.times) - 1
+		row     = make([]float64, len(table.values))
+		out     = make([]float64, numberOfColumns)
+	)
+	for newest := len(table.times) - 1; newest >= 0; newest-- {
+		moments.update(table.row(row, newest), 1)
+		start := window.Sub(table.times[newest])
+		for oldest > newest && table.times[oldest].Before(start) {
+			moments.update(table.row(row, oldest), -1)
+			oldest--
+		}
+		if start.Before(first) || moments.n < 2 {
+			continue
+		}
+		compute(out, &moments)
+		times = append(times, table.times[newest])
+		for c := range values {
+			values[c] = append(values[c], out[c])
+		}
+	}
+	slices.Reverse(times)
+	for c := range values {
+		slices.Reverse(values[c])
+	}
+	return NewTableFromValues(times, values)
+}
+
+func (table Table) row(out []float64, index int) []float64 {
+	for c := range table.values {
+		out[c] = table.values[c][index]
+	}
+	return out
+}
+
+// rollingMoments holds the sums needed for the means and covariances of the columns in a window.
+type rollingMoments struct {
+	n     float64
+	sum   []float64
+	cross [][]float64
+}
+
+func newRollingMoments(numberOfColumns int) rollingMoments {
+	moments := rollingMoments{
+		sum:   make([]float64, numberOfColumns),
+		cross: make([][]float64, numberOfColumns),
+	}
+	for i := range moments.cross {
+		moments.cross[i] = make([]float64, numberOfColumns)
+	}
+	return moments
+}
+
+// update adds (sign = 1) or removes (sign = -1) a row.
+func (moments *rollingMoments) update(row []float64, sign float64) {
+	moments.n += sign
+	for i, x := range row {
+		moments.sum[i] += sign * x
+		for j := i; j < len(row); j++ {
+			moments.cross[i][j] += sign * x * row[j]
+		}
+	}
+}
+
+func (moments *rollingMoments) mean(i int) float64 { return moments.sum[i] / moments.n }
+
+// covariance is the sample covariance of columns i and j.
+func (moments *rollingMoments) covariance(i, j int) float64 {
+	if j < i {
+		i, j = j, i
+	}
+	return (moments.cross[i][j] - moments.sum[i]*moments.sum[j]/moments.n) / (moments.n - 1)
+}
@@ -0,0 +1,101 @@
+package returns_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/stat"
+
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestTable_Rolling(t *testing.T) {
+	var times []time.Time
+	values := make([][]float64, 3)
+	for tm, i := time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC), 0; !tm.Before(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)); tm, i = tm.AddDate(0, 0, -1), i+1 {
+		if tm.Weekday() == time.Saturday || tm.Weekday() == time.Sunday {
+			continue
+		}
+		times = append(times, tm)
+		benchmark := 0.01 * math.Sin(float64(i))
+		values[0] = append(values[0], benchmark)
+		values[1] = append(values[1], 1.5*benchmark+0.004*math.Cos(float64(3*i)))
+		values[2] = append(values[2], 0.0001+0.00001*math.Sin(float64(7*i)))
+	}
+	table := returns.NewTableFromValues(times, values)
+	window := backtestconfig.OneYearWindow
+
+	windowValues := func(t *testing.T, tm time.Time) [][]float64 {
+		t.Helper()
+		return table.Between(tm, window.Sub(tm)).ColumnValues()
+	}
+
+	t.Run("risk", func(t *testing.T) {
+		result := table.RollingRisk(window)
+		require.Equal(t, 3, result.NumberOfColumns())
+		assert.Equal(t, table.LastTime(), result.LastTime())
+		assert.False(t, window.Sub(result.FirstTime()).Before(table.FirstTime()), "only full windows")
+		assert.True(t, window.Sub(result.FirstTime().AddDate(0, 0, -1)).Before(table.FirstTime()))
+
+		for i, tm := range result.Times() {
+			w := windowValues(t, tm)
+			for c := range w {
+				expected := calculate.AnnualizeRisk(calculate.RiskFromStdDev(w[c]), calculate.PeriodsPerYear)
+				require.InDelta(t, expected, result.ColumnValues()[c][i], 1e-9)
+			}
+		}
+	})
+
+	t.Run("sharpe ratio", func(t *testing.T) {
+		result := table.RollingSharpeRatio(window, 2)
+		tm := result.Times()[len(result.Times())/2]
+		w := windowValues(t, tm)
+		row, ok := result.Row(tm)
+		require.True(t, ok)
+		expected := (stat.Mean(w[1], nil) - stat.Mean(w[2], nil)) * calculate.PeriodsPerYear /
+			calculate.AnnualizeRisk(calculate.RiskFromStdDev(w[1]), calculate.PeriodsPerYear)
+		assert.InDelta(t, expected, row[1], 1e-9)
+	})
+
+	t.Run("beta and tracking error", func(t *testing.T) {
+		beta := table.RollingBeta(window, 0)
+		trackingError := table.RollingTrackingError(window, 0)
+		for i, tm := range beta.Times() {
+			w := windowValues(t, tm)
+			_, slope := stat.LinearRegression(w[0], w[1], nil, false)
+			require.InDelta(t, slope, beta.ColumnValues()[1][i], 1e-9)
+			require.InDelta(t, 1, beta.ColumnValues()[0][i], 1e-9)
+
+			excess := make([]float64, len(w[1]))
+			for j := range excess {
+				excess[j] = w[1][j] - w[0][j]
+			}
+			require.InDelta(t, calculate.AnnualizeRisk(calculate.RiskFromStdDev(excess), calculate.PeriodsPerYear), trackingError.ColumnValues()[1][i], 1e-9)
+			require.InDelta(t, 0, trackingError.ColumnValues()[0][i], 1e-6)
+		}
+	})
+
+	t.Run("correlation", func(t *testing.T) {
+		pairs := returns.ColumnPairs(3)
+		assert.Equal(t, [][2]int{{0, 1}, {0, 2}, {1, 2}}, pairs)
+
+		result := table.RollingCorrelation(window)
+		require.Equal(t, len(pairs), result.NumberOfColumns())
+		for i, tm := range result.Times() {
+			w := windowValues(t, tm)
+			for k, pair := range pairs {
+				require.InDelta(t, stat.Correlation(w[pair[0]], w[pair[1]], nil), result.ColumnValues()[k][i], 1e-9)
+			}
+		}
+	})
+
+	t.Run("shorter than window", func(t *testing.T) {
+		result := table.RollingRisk(backtestconfig.FiveYearWindow)
+		assert.Zero(t, result.NumberOfRows())
+	})
+}
@@ -290,37 +290,40 @@ func (table Table) RisksFromStdDev() []float64 {
 }
 
 func (table Table) AnnualizedRisk(column int) float64 {
-	return calculate.AnnualizeRisk(table.RiskFromStdDev(column), calculate.PeriodsPerYear)
+	return calculate.AnnualizeRisk(table.RiskFromStdDev(column), table.Frequency().PeriodsPerYear())
 }
 
 func (table Table) AnnualizedRisks() []float64 {
+	periodsPerYear := table.Frequency().PeriodsPerYear()
 	result := make([]float64, table.NumberOfColumns())
 	for i := range result {
-		result[i] = table.AnnualizedRisk(i)
+		result[i] = calculate.AnnualizeRisk(table.RiskFromStdDev(i), periodsPerYear)
 	}
 	return result
 }
 
 func (table Table) TimeWeightedReturn(column int) float64 {
-	return calculate.AnnualizedTimeWeightedReturn(table.values[column], calculate.PeriodsPerYear)
+	return calculate.AnnualizedTimeWeightedReturn(table.values[column], table.Frequency().PeriodsPerYear())
 }
 
 func (table Table) TimeWeightedReturns() []float64 {
+	periodsPerYear := table.Frequency().PeriodsPerYear()
 	result := make([]float64, table.NumberOfColumns())
 	for i := range table.values {
-		result[i] = table.TimeWeightedReturn(i)
+		result[i] = calculate.AnnualizedTimeWeightedReturn(table.values[i], periodsPerYear)
 	}
 	return result
 }
 
 func (table Table) AnnualizedArithmeticReturn(column int) float64 {
-	return calculate.AnnualizedArithmeticReturn(table.values[column], calculate.PeriodsPerYear)
+	return calculate.AnnualizedArithmeticReturn(table.values[column], table.Frequency().PeriodsPerYear())
 }
 
 func (table Table) AnnualizedArithmeticReturns() []float64 {
+	periodsPerYear := table.Frequency().PeriodsPerYear()
 	result := make([]float64, table.NumberOfColumns())
 	for i := range table.values {
-		result[i] = table.AnnualizedArithmeticReturn(i)
+		result[i] = calculate.AnnualizedArithmeticReturn(table.values[i], periodsPerYear)
 	}
 	return result
 }
@@ -464,6 +467,7 @@ func (table Table) ColumnGroupValues(group ColumnGroup) [][]float64 {
 }
 
 // AlignTables may be used to ensure multiple tables are date-aligned.
+// Use Alignment.AlignTables to choose how missing returns are handled and to get a report of the changes.
 func AlignTables(tables ...Table) (_ []Table, end, start time.Time, _ error) {
 	var (
 		table  Table
@@ -0,0 +1,84 @@
+package returns
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+// TailRisk has one period value at risk measures. Losses are negative returns.
+type TailRisk struct {
+	ConfidenceLevel float64 `json:"confidenceLevel" bson:"confidenceLevel"`
+	// Parametric is calculate.ParametricValueAtRisk (zero mean normal with the sample standard deviation).
+	Parametric    float64 `json:"parametric"    bson:"parametric"`
+	Historical    float64 `json:"historical"    bson:"historical"`
+	CornishFisher float64 `json:"cornishFisher" bson:"cornishFisher"`
+	// ConditionalValueAtRisk is the historical expected shortfall.
+	ConditionalValueAtRisk float64 `json:"conditionalValueAtRisk" bson:"conditionalValueAtRisk"`
+	// ParametricConditionalValueAtRisk is calculate.ParametricConditionalValueAtRisk (the same distribution as Parametric).
+	ParametricConditionalValueAtRisk float64 `json:"parametricConditionalValueAtRisk" bson:"parametricConditionalValueAtRisk"`
+}
+
+// AssetValueAtRisk is the parametric value at risk attributed to one column of a Table.
+type AssetValueAtRisk struct {
+	Marginal  float64 `json:"marginal"  bson:"marginal"`
+	Component float64 `json:"component" bson:"component"`
+}
+
+func (list List) TailRisk(confidenceLevel float64) TailRisk {
+	values := list.Values()
+	return TailRisk{
+		ConfidenceLevel:                  confidenceLevel,
+		Parametric:                       calculate.ParametricValueAtRisk(values, confidenceLevel),
+		Historical:                       calculate.HistoricalValueAtRisk(values, confidenceLevel),
+		CornishFisher:                    calculate.CornishFisherValueAtRisk(values, confidenceLevel),
+		ConditionalValueAtRisk:           calculate.HistoricalConditionalValueAtRisk(values, confidenceLevel),
+		ParametricConditionalValueAtRisk: calculate.ParametricConditionalValueAtRisk(values, confidenceLevel),
+	}
+}
+
+// WeightedReturns returns the returns of a portfolio rebalanced to weights every period.
+func (table Table) WeightedReturns(weights []float64) (List, error) {
+	if err := table.checkWeights(weights); err != nil {
+		return nil, err
+	}
+	result := make(List, len(table.times))
+	for i, tm := range table.times {
+		result[i].Time = tm
+		for c := range table.values {
+			result[i].Value += weights[c] * table.values[c][i]
+		}
+	}
+	return result, nil
+}
+
+// TailRisk returns the tail risk of WeightedReturns and the value at risk contributions of each column.
+func (table Table) TailRisk(weights []float64, confidenceLevel float64) (TailRisk, []AssetValueAtRisk, error) {
+	list, err := table.WeightedReturns(weights)
+	if err != nil {
+		return TailRisk{}, nil, err
+	}
+	marginal, component := calculate.ValueAtRiskContributions(weights, table.values, confidenceLevel)
+	assets := make([]AssetValueAtRisk, len(weights))
+	for i := range assets {
+		assets[i] = AssetValueAtRisk{Marginal: marginal[i], Component: component[i]}
+	}
+	return list.TailRisk(confidenceLevel), assets, nil
+}
+
+// MonteCarloValueAtRisk returns the value at risk and conditional value at risk of simulated weighted returns.
+// See calculate.MonteCarloValueAtRisk.
+func (table Table) MonteCarloValueAtRisk(weights []float64, confidenceLevel float64, simulations int, src rand.Source) (valueAtRisk, conditionalValueAtRisk float64, _ error) {
+	if err := table.checkWeights(weights); err != nil {
+		return 0, 0, err
+	}
+	return calculate.MonteCarloValueAtRisk(weights, table.values, confidenceLevel, simulations, src)
+}
+
+func (table Table) checkWeights(weights []float64) error {
+	if len(weights) != len(table.values) {
+		return fmt.Errorf("expected %d weights but got %d", len(table.values), len(weights))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package returns_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
+
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+func TestTable_TailRisk(t *testing.T) {
+	table := returns.NewTable([]returns.List{
+		{
+			rtn(t, "2021-01-08", 0.01),
+			rtn(t, "2021-01-07", -0.02),
+			rtn(t, "2021-01-06", 0.015),
+			rtn(t, "2021-01-05", -0.005),
+			rtn(t, "2021-01-04", 0.02),
+		},
+		{
+			rtn(t, "2021-01-08", 0.005),
+			rtn(t, "2021-01-07", -0.01),
+			rtn(t, "2021-01-06", 0.02),
+			rtn(t, "2021-01-05", 0.01),
+			rtn(t, "2021-01-04", -0.015),
+		},
+	})
+	weights := []float64{0.75, 0.25}
+
+	portfolio, err := table.WeightedReturns(weights)
+	require.NoError(t, err)
+	assert.Equal(t, table.Times(), portfolio.Times())
+	assert.InDeltaSlice(t, []float64{0.00875, -0.0175, 0.01625, -0.00125, 0.01125}, portfolio.Values(), 1e-12)
+
+	tailRisk, assets, err := table.TailRisk(weights, 0.8)
+	require.NoError(t, err)
+	assert.Equal(t, portfolio.TailRisk(0.8), tailRisk)
+	assert.Equal(t, 0.8, tailRisk.ConfidenceLevel)
+	assert.InDelta(t, -0.0175, tailRisk.Historical, 1e-12)
+	assert.InDelta(t, -0.0175, tailRisk.ConditionalValueAtRisk, 1e-12)
+	assert.InDelta(t, calculate.ParametricValueAtRisk(portfolio.Values(), 0.8), tailRisk.Parametric, 1e-12)
+	assert.Less(t, tailRisk.ParametricConditionalValueAtRisk, 0.0)
+	assert.LessOrEqual(t, tailRisk.ParametricConditionalValueAtRisk, tailRisk.Parametric, "expected shortfall is never less severe than value at risk")
+
+	require.Len(t, assets, 2)
+	component := []float64{assets[0].Component, assets[1].Component}
+	assert.InDelta(t, tailRisk.Parametric, floats.Sum(component), 1e-12)
+	assert.InDelta(t, assets[0].Component/0.75, assets[0].Marginal, 1e-12)
+
+	_, _, err = table.TailRisk([]float64{1}, 0.95)
+	assert.Error(t, err)
+
+	valueAtRisk, conditionalValueAtRisk, err := table.MonteCarloValueAtRisk(weights, 0.8, 1000, rand.NewPCG(1, 2))
+	require.NoError(t, err)
+	assert.Less(t, conditionalValueAtRisk, valueAtRisk)
+}
@@ -0,0 +1,108 @@
+package portfolio
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gonum.org/v1/gonum/floats"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/calculate"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// RiskDecomposition describes how each asset contributes to the volatility of a portfolio.
+// Volatilities are annualized using the frequency of the asset returns.
+type RiskDecomposition struct {
+	// Time is the newest asset return used.
+	Time       time.Time `json:"time"       bson:"time"`
+	Volatility float64   `json:"volatility" bson:"volatility"`
+	// DiversificationRatio is the sum of the weighted asset volatilities divided by the portfolio volatility.
+	// It does not depend on the scale of the weights.
+	DiversificationRatio  float64     `json:"diversificationRatio"  bson:"diversificationRatio"`
+	EffectiveNumberOfBets float64     `json:"effectiveNumberOfBets" bson:"effectiveNumberOfBets"`
+	Assets                []AssetRisk `json:"assets"                bson:"assets"`
+}
+
+// AssetRisk is the contribution of one asset to the volatility of a portfolio.
+type AssetRisk struct {
+	Component Component `json:"component" bson:"component"`
+	Weight    float64   `json:"weight"    bson:"weight"`
+	// Volatility is the standalone volatility of the asset.
+	Volatility float64 `json:"volatility" bson:"volatility"`
+	// MarginalContribution is the change in portfolio volatility for a small change in Weight.
+	MarginalContribution float64 `json:"marginalContribution" bson:"marginalContribution"`
+	// Contribution is Weight times MarginalContribution. The contributions sum to the portfolio volatility.
+	Contribution float64 `json:"contribution" bson:"contribution"`
+	// PercentContribution is Contribution divided by the portfolio volatility.
+	PercentContribution float64 `json:"percentContribution" bson:"percentContribution"`
+}
+
+// RiskDecomposition decomposes the volatility of the portfolio with weights using the asset returns
+// in the policy look back window ending at date. When the look back window is not set all the returns
+// up to date are used. The assets table must have a column for each asset.
+func (pf *Specification) RiskDecomposition(assets returns.Table, weights []float64, date time.Time) (RiskDecomposition, error) {
+	if assets.NumberOfColumns() != len(pf.Assets) {
+		return RiskDecomposition{}, fmt.Errorf("expected returns for %d assets but got %d columns", len(pf.Assets), assets.NumberOfColumns())
+	}
+	if len(weights) != len(pf.Assets) {
+		return RiskDecomposition{}, errAssetAndWeightsLenMismatch(pf)
+	}
+	window := pf.Policy.WeightsAlgorithmLookBack.Function(date, assets)
+	if window.NumberOfRows() < 2 {
+		return RiskDecomposition{}, fmt.Errorf("not enough asset returns on or before %s", date.Format(time.DateOnly))
+	}
+
+	periodsPerYear := window.Frequency().PeriodsPerYear()
+	risks := window.RisksFromStdDev()
+	correlations := window.CorrelationMatrix()
+	volatility, contributions := calculate.PortfolioVolatility(weights, risks, correlations)
+	if volatility == 0 {
+		return RiskDecomposition{}, fmt.Errorf("portfolio volatility is zero on %s", date.Format(time.DateOnly))
+	}
+	weightedRisk := floats.Dot(weights, risks)
+	bets, err := calculate.NumberOfBets(weightedRisk, volatility)
+	if err != nil {
+		return RiskDecomposition{}, err
+	}
+
+	result := RiskDecomposition{
+		Time:                  window.LastTime(),
+		Volatility:            calculate.AnnualizeRisk(volatility, periodsPerYear),
+		DiversificationRatio:  weightedRisk / volatility,
+		EffectiveNumberOfBets: bets,
+		Assets:                make([]AssetRisk, len(weights)),
+	}
+	percentContributions := calculate.RiskWeights(volatility, contributions)
+	for i, component := range pf.Assets {
+		asset := AssetRisk{
+			Component:           component,
+			Weight:              weights[i],
+			Volatility:          calculate.AnnualizeRisk(risks[i], periodsPerYear),
+			Contribution:        calculate.AnnualizeRisk(contributions[i], periodsPerYear),
+			PercentContribution: percentContributions[i],
+		}
+		if weights[i] != 0 {
+			asset.MarginalContribution = asset.Contribution / weights[i]
+		}
+		result.Assets[i] = asset
+	}
+	return result, nil
+}
+
+// BacktestRiskDecomposition decomposes the risk of the backtest weights at the end of date
+// as fractions of portfolio value (see backtest.Result.ValueWeights). See RiskDecomposition.
+func (pf *Specification) BacktestRiskDecomposition(assets returns.Table, result backtest.Result, date time.Time) (RiskDecomposition, error) {
+	index := slices.IndexFunc(result.ReturnsTable.Times(), date.Equal)
+	if index < 0 || index >= len(result.Weights) {
+		return RiskDecomposition{}, fmt.Errorf("backtest does not have weights on %s", date.Format(time.DateOnly))
+	}
+	return pf.RiskDecomposition(assets, result.ValueWeights(index), date)
+}
+
+// PolicyRiskDecomposition decomposes the risk of the final policy weights of a backtest
+// using the asset returns ending on the last day of the backtest. See RiskDecomposition.
+func (pf *Specification) PolicyRiskDecomposition(assets returns.Table, result backtest.Result) (RiskDecomposition, error) {
+	return pf.RiskDecomposition(assets, result.FinalPolicyWeights, result.ReturnsTable.LastTime())
+}
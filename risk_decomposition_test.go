@@ -0,0 +1,95 @@
+package portfolio_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+)
+
+func TestSpecification_RiskDecomposition(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets: [SPY, AGG]
+  policy:
+    weights: [60, 40]
+    weights_algorithm: Constant Weights
+    weights_algorithm_look_back_window: 1 Year
+    rebalancing_interval: Quarterly
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, pf.Spec.Assets...)
+	require.NoError(t, err)
+	result, err := pf.Spec.Backtest(ctx, assets, nil)
+	require.NoError(t, err)
+
+	t.Run("policy weights", func(t *testing.T) {
+		decomposition, err := pf.Spec.PolicyRiskDecomposition(assets, result)
+		require.NoError(t, err)
+		assert.Equal(t, result.ReturnsTable.LastTime(), decomposition.Time)
+
+		window := assets.Between(decomposition.Time, backtestconfig.OneYearWindow.Sub(decomposition.Time))
+		portfolioReturns, err := window.WeightedReturns(result.FinalPolicyWeights)
+		require.NoError(t, err)
+		assert.InDelta(t, portfolioReturns.AnnualizedRisk(), decomposition.Volatility, 1e-9)
+
+		require.Len(t, decomposition.Assets, 2)
+		spy, agg := decomposition.Assets[0], decomposition.Assets[1]
+		assert.Equal(t, "SPY", spy.Component.ID)
+		assert.InDelta(t, 0.6, spy.Weight, 1e-9)
+		assert.InDelta(t, window.AnnualizedRisk(0), spy.Volatility, 1e-9)
+		assert.InDelta(t, decomposition.Volatility, spy.Contribution+agg.Contribution, 1e-9)
+		assert.InDelta(t, 1, spy.PercentContribution+agg.PercentContribution, 1e-9)
+		assert.InDelta(t, spy.Contribution/spy.Weight, spy.MarginalContribution, 1e-9)
+		assert.Greater(t, spy.PercentContribution, spy.Weight, "equities dominate the risk of a 60/40 portfolio")
+
+		assert.Greater(t, decomposition.DiversificationRatio, 1.0)
+		assert.InDelta(t, decomposition.DiversificationRatio*decomposition.DiversificationRatio, decomposition.EffectiveNumberOfBets, 1e-9)
+	})
+
+	t.Run("backtest date", func(t *testing.T) {
+		day := date("2020-03-31")
+		decomposition, err := pf.Spec.BacktestRiskDecomposition(assets, result, day)
+		require.NoError(t, err)
+		assert.Equal(t, day, decomposition.Time)
+
+		index := slices.IndexFunc(result.ReturnsTable.Times(), day.Equal)
+		weights := result.Weights[index]
+		valueWeights := []float64{weights[0] / (weights[0] + weights[1]), weights[1] / (weights[0] + weights[1])}
+		assert.InDelta(t, valueWeights[0], decomposition.Assets[0].Weight, 1e-12, "drifted weights are fractions of portfolio value")
+		assert.InDelta(t, 1, decomposition.Assets[0].Weight+decomposition.Assets[1].Weight, 1e-12)
+		assert.NotEqual(t, 0.6, decomposition.Assets[0].Weight, "drifted weights are used")
+
+		scaled, err := pf.Spec.RiskDecomposition(assets, valueWeights, day)
+		require.NoError(t, err)
+		assert.InDelta(t, scaled.Volatility, decomposition.Volatility, 1e-12)
+		assert.InDelta(t, scaled.Assets[0].Contribution, decomposition.Assets[0].Contribution, 1e-12)
+		assert.InDelta(t, scaled.DiversificationRatio, decomposition.DiversificationRatio, 1e-9)
+
+		_, err = pf.Spec.BacktestRiskDecomposition(assets, result, date("2020-03-29"))
+		assert.ErrorContains(t, err, "backtest does not have weights on 2020-03-29")
+	})
+
+	t.Run("long short weights", func(t *testing.T) {
+		decomposition, err := pf.Spec.RiskDecomposition(assets, []float64{1.5, -0.5}, assets.LastTime())
+		require.NoError(t, err)
+		spy, agg := decomposition.Assets[0], decomposition.Assets[1]
+		assert.InDelta(t, (1.5*spy.Volatility-0.5*agg.Volatility)/decomposition.Volatility, decomposition.DiversificationRatio, 1e-9)
+	})
+
+	t.Run("mismatched weights", func(t *testing.T) {
+		_, err := pf.Spec.RiskDecomposition(assets, []float64{1}, assets.LastTime())
+		assert.Error(t, err)
+	})
+}
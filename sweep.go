@@ -0,0 +1,122 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/returns"
+)
+
+// PolicyGrid lists policy values to combine in Specification.Sweep.
+// An empty field keeps the value from the specification policy.
+type PolicyGrid struct {
+	WeightsAlgorithms         []string                  `json:"weightsAlgorithms"         yaml:"weights_algorithms,omitempty"                   bson:"weights_algorithms"`
+	WeightsAlgorithmLookBacks []backtestconfig.Window   `json:"weightsAlgorithmLookBacks" yaml:"weights_algorithm_look_back_windows,omitempty" bson:"weights_algorithm_look_back_windows"`
+	WeightsUpdatingIntervals  []backtestconfig.Interval `json:"weightsUpdatingIntervals"  yaml:"weights_updating_intervals,omitempty"           bson:"weights_updating_intervals"`
+	RebalancingIntervals      []backtestconfig.Interval `json:"rebalancingIntervals"      yaml:"rebalancing_intervals,omitempty"                bson:"rebalancing_intervals"`
+}
+
+// Policies returns every combination of the grid values applied to base.
+// The last field (RebalancingIntervals) varies fastest.
+func (grid PolicyGrid) Policies(base Policy) []Policy {
+	policies := []Policy{base}
+	policies = expandPolicies(policies, grid.WeightsAlgorithms, func(p *Policy, v string) { p.WeightsAlgorithm = v })
+	policies = expandPolicies(policies, grid.WeightsAlgorithmLookBacks, func(p *Policy, v backtestconfig.Window) { p.WeightsAlgorithmLookBack = v })
+	policies = expandPolicies(policies, grid.WeightsUpdatingIntervals, func(p *Policy, v backtestconfig.Interval) { p.WeightsUpdatingInterval = v })
+	policies = expandPolicies(policies, grid.RebalancingIntervals, func(p *Policy, v backtestconfig.Interval) { p.RebalancingInterval = v })
+	return policies
+}
+
+func expandPolicies[T any](policies []Policy, values []T, set func(*Policy, T)) []Policy {
+	if len(values) == 0 {
+		return policies
+	}
+	result := make([]Policy, 0, len(policies)*len(values))
+	for _, policy := range policies {
+		for _, value := range values {
+			p := policy
+			set(&p, value)
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// SweepResult is the backtest of one policy from a PolicyGrid.
+// When the backtest fails, Error is set and Result and Metrics are empty.
+type SweepResult struct {
+	Policy  Policy           `json:"policy"  bson:"policy"`
+	Result  backtest.Result  `json:"-"       bson:"-"`
+	Metrics backtest.Metrics `json:"metrics" bson:"metrics"`
+	Error   string           `json:"error"   bson:"error"`
+}
+
+// Sweep backtests every policy in grid using the same asset returns.
+// The backtests run concurrently on at most workers goroutines; when workers is not positive, runtime.GOMAXPROCS is used.
+// The assets table is shared by the backtests and must not be modified while Sweep runs.
+//
+// Results are in the order of PolicyGrid.Policies. An error from a single backtest is recorded
+// in its SweepResult; Sweep only returns an error when a policy is not valid or ctx is done.
+func (pf *Specification) Sweep(ctx context.Context, assets returns.Table, grid PolicyGrid, workers int, opts ...backtest.Option) ([]SweepResult, error) {
+	policies := grid.Policies(pf.Policy)
+	var list []error
+	for _, policy := range policies {
+		list = append(list, policy.Validate())
+	}
+	if err := errors.Join(list...); err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(policies))
+
+	results := make([]SweepResult, len(policies))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = pf.sweepBacktest(ctx, assets, policies[i], opts)
+			}
+		}()
+	}
+send:
+	for i := range policies {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (pf *Specification) sweepBacktest(ctx context.Context, assets returns.Table, policy Policy, opts []backtest.Option) SweepResult {
+	spec := *pf
+	spec.Policy = policy
+	if spec.Policy.WeightsAlgorithm == "" {
+		spec.setDefaultPolicyWeightAlgorithm()
+	}
+	sr := SweepResult{Policy: spec.Policy}
+	result, err := spec.Backtest(ctx, assets, nil, opts...)
+	if err != nil {
+		sr.Error = err.Error()
+		return sr
+	}
+	sr.Result = result
+	sr.Metrics = backtest.NewReport(result, nil, nil).Portfolio.Overall
+	return sr
+}
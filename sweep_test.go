@@ -0,0 +1,91 @@
+package portfolio_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portfoliotree/portfolio"
+	"github.com/portfoliotree/portfolio/allocation"
+	"github.com/portfoliotree/portfolio/backtest"
+	"github.com/portfoliotree/portfolio/backtest/backtestconfig"
+	"github.com/portfoliotree/portfolio/portfoliotest"
+)
+
+func TestSpecification_Sweep(t *testing.T) {
+	// language=yaml
+	specYAML := `---
+type: Portfolio
+spec:
+  assets: [SPY, AGG]
+  policy:
+    weights: [60, 40]
+    weights_algorithm: Constant Weights
+    rebalancing_interval: Quarterly
+`
+	pf, err := portfolio.ParseOneDocument(specYAML)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assets, err := portfoliotest.ComponentReturnsProvider().ComponentReturnsTable(ctx, pf.Spec.Assets...)
+	require.NoError(t, err)
+
+	grid := portfolio.PolicyGrid{
+		WeightsAlgorithms:         []string{allocation.ConstantWeightsAlgorithmName, allocation.EqualWeightsAlgorithmName, "Unknown"},
+		WeightsAlgorithmLookBacks: []backtestconfig.Window{backtestconfig.OneYearWindow},
+		RebalancingIntervals:      []backtestconfig.Interval{backtestconfig.IntervalMonthly, backtestconfig.IntervalAnnually},
+	}
+
+	t.Run("policies", func(t *testing.T) {
+		policies := grid.Policies(pf.Spec.Policy)
+		require.Len(t, policies, 6)
+		assert.Equal(t, allocation.ConstantWeightsAlgorithmName, policies[0].WeightsAlgorithm)
+		assert.Equal(t, backtestconfig.IntervalMonthly, policies[0].RebalancingInterval)
+		assert.Equal(t, backtestconfig.IntervalAnnually, policies[1].RebalancingInterval)
+		assert.Equal(t, allocation.EqualWeightsAlgorithmName, policies[2].WeightsAlgorithm)
+		for _, policy := range policies {
+			assert.Equal(t, backtestconfig.OneYearWindow, policy.WeightsAlgorithmLookBack)
+			assert.Equal(t, []float64{60, 40}, policy.Weights)
+		}
+		assert.Equal(t, []portfolio.Policy{pf.Spec.Policy}, portfolio.PolicyGrid{}.Policies(pf.Spec.Policy))
+	})
+
+	results, err := pf.Spec.Sweep(ctx, assets, grid, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+	assert.Equal(t, backtestconfig.IntervalQuarterly, pf.Spec.Policy.RebalancingInterval, "the specification is not changed")
+
+	for i, policy := range grid.Policies(pf.Spec.Policy) {
+		assert.Equal(t, policy, results[i].Policy)
+		if policy.WeightsAlgorithm == "Unknown" {
+			assert.Equal(t, "unknown algorithm", results[i].Error)
+			assert.Zero(t, results[i].Result.ReturnsTable.NumberOfRows())
+			continue
+		}
+		require.Empty(t, results[i].Error)
+
+		spec := pf.Spec
+		spec.Policy = policy
+		expected, err := spec.Backtest(ctx, assets, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Returns(), results[i].Result.Returns())
+		assert.Equal(t, backtest.NewReport(expected, nil, nil).Portfolio.Overall, results[i].Metrics)
+	}
+	assert.NotEqual(t, results[0].Metrics.AnnualizedRisk, results[2].Metrics.AnnualizedRisk)
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := pf.Spec.Sweep(ctx, assets, portfolio.PolicyGrid{
+			RebalancingIntervals: []backtestconfig.Interval{"Sometimes"},
+		}, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := pf.Spec.Sweep(ctx, assets, grid, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,72 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/portfoliotree/portfolio/calculate"
+)
+
+// View is an investor view used by the Black-Litterman weights algorithm.
+// An absolute view lists Assets and the expected annualized Return of their equal weighted portfolio.
+// A relative view also lists RelativeTo assets and Return is the expected annualized outperformance.
+// Confidence must be greater than zero and at most one.
+type View struct {
+	Assets     []string `yaml:"assets"                json:"assets"                bson:"assets"`
+	RelativeTo []string `yaml:"relative_to,omitempty" json:"relativeTo,omitempty" bson:"relative_to,omitempty"`
+	Return     float64  `yaml:"return"                json:"return"                bson:"return"`
+	Confidence float64  `yaml:"confidence"            json:"confidence"            bson:"confidence"`
+}
+
+// BlackLittermanViews converts the policy views into pick matrix rows for calculate.BlackLittermanWeights.
+// It returns an error when a view references an asset that is not in the portfolio or is otherwise malformed.
+func (pf *Specification) BlackLittermanViews() ([]calculate.View, error) {
+	var (
+		views = make([]calculate.View, 0, len(pf.Policy.Views))
+		list  []error
+	)
+	for vi, view := range pf.Policy.Views {
+		ws, err := pf.viewWeights(view)
+		if err != nil {
+			list = append(list, fmt.Errorf("view %d: %w", vi, err))
+			continue
+		}
+		v := calculate.View{Weights: ws, Return: view.Return, Confidence: view.Confidence}
+		if err := v.Validate(len(pf.Assets)); err != nil {
+			list = append(list, fmt.Errorf("view %d: %w", vi, err))
+			continue
+		}
+		views = append(views, v)
+	}
+	if err := errors.Join(list...); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+func (pf *Specification) viewWeights(view View) ([]float64, error) {
+	if len(view.Assets) == 0 {
+		return nil, errors.New("view must list at least one asset")
+	}
+	ws := make([]float64, len(pf.Assets))
+	for _, side := range []struct {
+		ids    []string
+		weight float64
+	}{
+		{ids: view.Assets, weight: 1},
+		{ids: view.RelativeTo, weight: -1},
+	} {
+		for _, id := range side.ids {
+			index := slices.IndexFunc(pf.Assets, func(c Component) bool { return c.ID == id })
+			if index < 0 {
+				return nil, fmt.Errorf("asset %q is not in the portfolio", id)
+			}
+			if ws[index] != 0 {
+				return nil, fmt.Errorf("asset %q is listed more than once", id)
+			}
+			ws[index] = side.weight / float64(len(side.ids))
+		}
+	}
+	return ws, nil
+}